@@ -0,0 +1,163 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const AUTO_PART = "auto"
+
+// The bump parts Conventional Commits call for, from least to most.
+var AUTO_PARTS = []string{"patch", "minor", "major"}
+
+// CONVENTIONAL_COMMIT_PARTS maps the Conventional Commit types that call for
+// a new version to the part they bump. A breaking change of any type bumps
+// the major version.
+var CONVENTIONAL_COMMIT_PARTS = map[string]string{
+	"feat": "minor",
+	"fix":  "patch",
+	"perf": "patch",
+}
+
+// e.g. `feat(api)!: drop the v1 endpoints`
+var CONVENTIONAL_COMMIT_RX = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?: `)
+
+var BREAKING_CHANGE_RX = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// ConventionalCommit is a commit read as a Conventional Commit. Type is
+// empty when the message does not follow the convention, and Part is the
+// bump the commit calls for, if any.
+type ConventionalCommit struct {
+	Hash     string
+	Subject  string
+	Type     string
+	Breaking bool
+	Part     string
+}
+
+func parseConventionalCommit(hash string, message string) ConventionalCommit {
+	message = strings.TrimSpace(message)
+	subject, _, _ := strings.Cut(message, "\n")
+	commit := ConventionalCommit{Hash: hash, Subject: subject}
+
+	match := CONVENTIONAL_COMMIT_RX.FindStringSubmatch(subject)
+	if match == nil {
+		return commit
+	}
+	commit.Type = strings.ToLower(match[1])
+	commit.Breaking = match[2] == "!" || BREAKING_CHANGE_RX.MatchString(message)
+	commit.Part = CONVENTIONAL_COMMIT_PARTS[commit.Type]
+	if commit.Breaking {
+		commit.Part = "major"
+	}
+	return commit
+}
+
+// AutoBump is the bump picked from the commits since the last version tag.
+// Tag is that tag, empty when no version has been tagged yet, and Commits
+// are all the commits since, newest first. ZeroMajor is set when a breaking
+// change only bumps the minor version, as the major version is still 0.
+type AutoBump struct {
+	Part      string
+	Tag       string
+	Commits   []ConventionalCommit
+	ZeroMajor bool
+}
+
+// AutoBump reads the commits since the last version tag that touch the
+// project as Conventional Commits and picks the bump they call for: a
+// breaking change bumps the major version, a feat the minor and a fix or
+// perf the patch. Calver versions are rolled to today's date instead.
+func (p *Project) AutoBump() (*AutoBump, error) {
+	current, err := p.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := gitPrefix(p.config.dir); err != nil {
+		return nil, err
+	}
+	tag, err := p.lastVersionTag()
+	if err != nil {
+		return nil, err
+	}
+	commits, err := gitCommits(p.config.dir, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	auto := AutoBump{Tag: tag, Commits: commits}
+	for _, commit := range commits {
+		if IndexOf(&AUTO_PARTS, commit.Part) > IndexOf(&AUTO_PARTS, auto.Part) {
+			auto.Part = commit.Part
+		}
+	}
+
+	since := "the first commit"
+	if tag != "" {
+		since = tag
+	}
+	switch {
+	case auto.Part == "":
+		return nil, fmt.Errorf("none of the %d commits since %s is a feat, fix, perf or breaking change", len(commits), since)
+	case p.config.scheme.name() == CALVER_SCHEME:
+		auto.Part = "calver"
+	case auto.Part == "major" && normalizeNumber(current.major) == "0":
+		auto.Part = "minor"
+		auto.ZeroMajor = true
+	}
+	return &auto, nil
+}
+
+// lastVersionTag is the tag of the highest version among the tags reachable
+// from HEAD that fit the tag_name template, or an empty string when there
+// are none.
+func (p *Project) lastVersionTag() (string, error) {
+	template := strings.ReplaceAll(p.tagTemplate(), NAME_PLACEHOLDER, p.config.name)
+	prefix, suffix, _ := strings.Cut(template, VERSION_PLACEHOLDER)
+
+	output, err := runGit(p.config.dir, "tag", "--merged", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	last := ""
+	var highest *Version
+	for _, tag := range strings.Fields(output) {
+		if !strings.HasPrefix(tag, prefix) || !strings.HasSuffix(tag, suffix) || len(tag) < len(prefix)+len(suffix) {
+			continue
+		}
+		text := tag[len(prefix) : len(tag)-len(suffix)]
+		v, length, err := p.config.scheme.parse(text)
+		if err != nil || length != len(text) {
+			continue
+		}
+		if highest == nil || v.compare(highest) > 0 {
+			highest, last = v, tag
+		}
+	}
+	return last, nil
+}
+
+// gitCommits reads the commits since tag, or all of them without one, that
+// touch dir. Merge commits are left out.
+func gitCommits(dir string, tag string) ([]ConventionalCommit, error) {
+	revisions := "HEAD"
+	if tag != "" {
+		revisions = tag + "..HEAD"
+	}
+	output, err := runGit(dir, "log", "--no-merges", "--format=%H%x1f%B%x1e", revisions, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []ConventionalCommit{}
+	for _, entry := range strings.Split(output, "\x1e") {
+		hash, message, found := strings.Cut(strings.TrimSpace(entry), "\x1f")
+		if found {
+			commits = append(commits, parseConventionalCommit(hash, message))
+		}
+	}
+	return commits, nil
+}
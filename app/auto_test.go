@@ -0,0 +1,126 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		message  string
+		kind     string
+		breaking bool
+		part     string
+	}{
+		{"feat: add login", "feat", false, "minor"},
+		{"feat(api): add login", "feat", false, "minor"},
+		{"fix: handle empty files", "fix", false, "patch"},
+		{"perf(search): cache the finders", "perf", false, "patch"},
+		{"docs: explain --auto", "docs", false, ""},
+		{"feat!: drop the v1 endpoints", "feat", true, "major"},
+		{"refactor(api)!: rename the handlers", "refactor", true, "major"},
+		{"fix: handle empty files\n\nBREAKING CHANGE: empty files are an error", "fix", true, "major"},
+		{"fix: handle empty files\n\nBREAKING-CHANGE: empty files are an error", "fix", true, "major"},
+		{"fix: mention a BREAKING CHANGE: inline", "fix", false, "patch"},
+		{"Merge branch 'main'", "", false, ""},
+		{"feat:no space", "", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			commit := parseConventionalCommit("1a2b3c4", tt.message)
+			assert.Equal(t, tt.kind, commit.Type)
+			assert.Equal(t, tt.breaking, commit.Breaking)
+			assert.Equal(t, tt.part, commit.Part)
+		})
+	}
+}
+
+// commit commits a change to the api project.
+func (suite *GitTestSuite) commit(message string) {
+	suite.writeFile("api/notes.txt", message)
+	suite.git("add", "--all")
+	suite.git("commit", "--quiet", "--message", message)
+}
+
+func (suite *GitTestSuite) TestAutoBump() {
+	suite.git("tag", "v1.1.0")
+	suite.commit("fix: handle empty files")
+	suite.git("tag", "v1.2.0")
+	suite.commit("docs: explain --auto")
+	suite.commit("fix: handle empty files")
+
+	project, _ := suite.plan("patch")
+	auto, err := project.AutoBump()
+	suite.Nil(err)
+	suite.Equal("patch", auto.Part)
+	suite.Equal("v1.2.0", auto.Tag)
+	suite.Equal(2, len(auto.Commits))
+	suite.Equal("fix: handle empty files", auto.Commits[0].Subject)
+
+	suite.commit("feat(api): add login")
+	auto, err = project.AutoBump()
+	suite.Nil(err)
+	suite.Equal("minor", auto.Part)
+
+	suite.commit("refactor!: rename the handlers")
+	auto, err = project.AutoBump()
+	suite.Nil(err)
+	suite.Equal("major", auto.Part)
+	suite.False(auto.ZeroMajor)
+}
+
+func (suite *GitTestSuite) TestAutoBumpZeroMajor() {
+	suite.writeFile("api/main.go", "package main\n\nconst VERSION = \"0.4.1\"\n")
+	suite.writeFile("api/version.txt", "version 0.4.1\n")
+	suite.commit("feat!: drop the v1 endpoints")
+
+	project, _ := suite.plan("patch")
+	auto, err := project.AutoBump()
+	suite.Nil(err)
+	suite.Equal("minor", auto.Part)
+	suite.True(auto.ZeroMajor)
+	suite.Equal("", auto.Tag)
+	suite.Equal(2, len(auto.Commits))
+}
+
+func (suite *GitTestSuite) TestAutoBumpWithoutChanges() {
+	suite.git("tag", "v1.2.0")
+	suite.commit("chore: update the dependencies")
+
+	project, _ := suite.plan("patch")
+	_, err := project.AutoBump()
+	suite.Equal("none of the 1 commits since v1.2.0 is a feat, fix, perf or breaking change", fmt.Sprint(err))
+}
+
+func (suite *GitTestSuite) TestAutoBumpOutsideProject() {
+	suite.git("tag", "v1.2.0")
+	suite.writeFile("README.md", "# api v1\n")
+	suite.git("commit", "--quiet", "--all", "--message", "feat: document the api")
+	suite.commit("fix: handle empty files")
+
+	project, _ := suite.plan("patch")
+	auto, err := project.AutoBump()
+	suite.Nil(err)
+	suite.Equal("patch", auto.Part)
+	suite.Equal(1, len(auto.Commits))
+}
+
+func (suite *GitTestSuite) TestLastVersionTag() {
+	suite.git("tag", "v1.2.0")
+	suite.git("tag", "v1.10.0")
+	suite.git("tag", "v1.3.0-rc.1")
+	suite.git("tag", "api-v9.0.0")
+	suite.git("tag", "latest")
+
+	project, _ := suite.plan("patch")
+	tag, err := project.lastVersionTag()
+	suite.Nil(err)
+	suite.Equal("v1.10.0", tag)
+
+	project.config.name = "api"
+	tag, err = project.lastVersionTag()
+	suite.Nil(err)
+	suite.Equal("api-v9.0.0", tag)
+}
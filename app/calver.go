@@ -0,0 +1,230 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CALVER_DEFAULT_FORMAT = "YYYY.0M.MICRO"
+	CALVER_MICRO          = "MICRO"
+)
+
+// CALVER_TOKENS maps each calver format token to the regex that matches it.
+var CALVER_TOKENS = map[string]string{
+	"YYYY":       `\d{4}`,
+	"YY":         `\d{1,3}`,
+	"0Y":         `\d{2,3}`,
+	"MM":         `\d{1,2}`,
+	"0M":         `\d{2}`,
+	"WW":         `\d{1,2}`,
+	"0W":         `\d{2}`,
+	"DD":         `\d{1,2}`,
+	"0D":         `\d{2}`,
+	CALVER_MICRO: `\d+`,
+}
+
+// calverScheme implements calendar versioning for a format such as
+// `YYYY.0M.MICRO`. The values of the format's tokens are kept, in order, in
+// the major, minor, patch and segments fields, and a calver version can carry
+// the same pre-release as a SemVer one (2026.10.3-beta.1).
+//
+// now is the clock used to roll the version to today's date.
+type calverScheme struct {
+	tokens []string
+	rx     *regexp.Regexp
+	ladder *releaseLadder
+	now    func() time.Time
+}
+
+func newCalverScheme(calverFormat string, ladder *releaseLadder, now func() time.Time) (calverScheme, error) {
+	if calverFormat == "" {
+		calverFormat = CALVER_DEFAULT_FORMAT
+	}
+
+	s := calverScheme{
+		tokens: strings.Split(calverFormat, "."),
+		ladder: ladder,
+		now:    now,
+	}
+	if len(s.tokens) < 2 {
+		return s, fmt.Errorf("invalid calver_format: %s needs at least two segments", calverFormat)
+	}
+	for index, token := range s.tokens {
+		if _, found := CALVER_TOKENS[token]; !found {
+			return s, fmt.Errorf("invalid calver_format: unknown token `%s` in %s", token, calverFormat)
+		}
+		if token == CALVER_MICRO && index != len(s.tokens)-1 {
+			return s, fmt.Errorf("invalid calver_format: MICRO must be the last token in %s", calverFormat)
+		}
+	}
+
+	s.rx = regexp.MustCompile(`^` + s.corePattern())
+	return s, nil
+}
+
+func (s calverScheme) name() string {
+	return CALVER_SCHEME
+}
+
+func (s calverScheme) corePattern() string {
+	patterns := []string{}
+	for _, token := range s.tokens {
+		patterns = append(patterns, `(`+CALVER_TOKENS[token]+`)`)
+	}
+	return strings.Join(patterns, `\.`)
+}
+
+func (s calverScheme) pattern() string {
+	return s.corePattern() + VERSION_SUFFIX
+}
+
+func (s calverScheme) parse(text string) (*Version, int, error) {
+	match := s.rx.FindStringSubmatch(text)
+	if match == nil {
+		return nil, 0, &ParseError{Kind: "calver version", Text: text}
+	}
+
+	v := Version{scheme: s}
+	v.setCalendarSegments(match[1:])
+
+	pos := len(match[0])
+	pos += parseVersionSuffix(&v, text[pos:])
+	return &v, pos, nil
+}
+
+func (v *Version) setCalendarSegments(values []string) {
+	for index, value := range values {
+		value = normalizeNumber(value)
+		switch index {
+		case 0:
+			v.major = value
+		case 1:
+			v.minor = value
+		case 2:
+			v.patch = value
+		default:
+			v.segments = append(v.segments, value)
+		}
+	}
+}
+
+func (s calverScheme) format(v *Version, f *Formatter) string {
+	output := []string{}
+	for index, value := range v.releaseSegments() {
+		if strings.HasPrefix(s.tokens[index], "0") && len(value) < 2 {
+			value = "0" + value
+		}
+		output = append(output, value)
+	}
+	return strings.Join(output, ".") + f.formatPreRelease(v)
+}
+
+// today returns the value of every date token for the scheme's clock.
+func (s calverScheme) today() map[string]string {
+	now := s.now()
+	year, week := now.Year(), 0
+	if IndexOf(&s.tokens, "WW") != -1 || IndexOf(&s.tokens, "0W") != -1 {
+		// weeks are ISO weeks, which belong to the ISO year
+		year, week = now.ISOWeek()
+	}
+	return map[string]string{
+		"YYYY": strconv.Itoa(year),
+		"YY":   strconv.Itoa(year - 2000),
+		"0Y":   strconv.Itoa(year - 2000),
+		"MM":   strconv.Itoa(int(now.Month())),
+		"0M":   strconv.Itoa(int(now.Month())),
+		"WW":   strconv.Itoa(week),
+		"0W":   strconv.Itoa(week),
+		"DD":   strconv.Itoa(now.Day()),
+		"0D":   strconv.Itoa(now.Day()),
+	}
+}
+
+// bumpToToday rolls the date segments to today's date. MICRO is reset when
+// the date changed and bumped when it did not.
+func (s calverScheme) bumpToToday(v *Version) (Version, error) {
+	today := s.today()
+	current := v.releaseSegments()
+
+	values := []string{}
+	dateChanged := false
+	for index, token := range s.tokens {
+		if token == CALVER_MICRO {
+			continue
+		}
+		values = append(values, today[token])
+		dateChanged = dateChanged || today[token] != current[index]
+	}
+
+	if IndexOf(&s.tokens, CALVER_MICRO) != -1 {
+		if dateChanged {
+			values = append(values, "0")
+		} else {
+			values = append(values, incrementNumber(current[len(current)-1]))
+		}
+	} else if !dateChanged {
+		return Version{}, errors.New("the version is already at today's date and the calver_format has no MICRO segment")
+	}
+
+	nv := Version{scheme: s}
+	nv.setCalendarSegments(values)
+	if nv.compare(v) < 0 {
+		return Version{}, fmt.Errorf("today's date would move the version back from %s", v.toString())
+	}
+	return nv, nil
+}
+
+// bump rolls the version with --calver and bumps MICRO with --patch. The
+// pre-release options work as they do for SemVer.
+func (s calverScheme) bump(v *Version, part string, preRelease string) (Version, error) {
+	nv := v.copy()
+
+	var err error
+	switch part {
+	case "calver":
+		nv, err = s.bumpToToday(&nv)
+		if err != nil {
+			return Version{}, err
+		}
+		part = ""
+	case "patch":
+		if s.tokens[len(s.tokens)-1] != CALVER_MICRO {
+			return Version{}, &UsageError{Err: errors.New("--patch needs a MICRO segment in the calver_format")}
+		}
+		values := nv.releaseSegments()
+		values[len(values)-1] = incrementNumber(values[len(values)-1])
+		nv = Version{scheme: s}
+		nv.setCalendarSegments(values)
+		part = ""
+	case "major", "minor":
+		return Version{}, &UsageError{Err: fmt.Errorf("--%s is not supported by the calver version scheme, use --calver", part)}
+	}
+
+	nv, err = nv.bumpSemVer(part, preRelease)
+	if err != nil {
+		return Version{}, err
+	}
+	if err := checkBumpOrder(v, &nv); err != nil {
+		return Version{}, err
+	}
+	return nv, nil
+}
+
+func (s calverScheme) releases() *releaseLadder {
+	return s.ladder
+}
+
+func (s calverScheme) compare(v *Version, other *Version) int {
+	segments, otherSegments := v.releaseSegments(), other.releaseSegments()
+	for index := 0; index < len(segments) && index < len(otherSegments); index++ {
+		if result := compareNumeric(segments[index], otherSegments[index]); result != 0 {
+			return result
+		}
+	}
+	return comparePreRelease(v.preReleaseIdentifiers(), other.preReleaseIdentifiers())
+}
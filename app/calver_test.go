@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixedClock(year int, month time.Month, day int) func() time.Time {
+	return func() time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	}
+}
+
+func newTestCalverScheme(t *testing.T, calverFormat string, year int, month time.Month, day int) calverScheme {
+	s, err := newCalverScheme(calverFormat, DEFAULT_RELEASES, fixedClock(year, month, day))
+	assert.Nil(t, err)
+	return s
+}
+
+func parseCalver(t *testing.T, s calverScheme, text string) *Version {
+	v, length, err := s.parse(text)
+	assert.Nil(t, err)
+	assert.Equal(t, len(text), length)
+	return v
+}
+
+func TestCalverInvalidFormat(t *testing.T) {
+	for _, calverFormat := range []string{"YYYY", "YYYY.QQ", "MICRO.YYYY", "YYYY-MM"} {
+		t.Run(calverFormat, func(t *testing.T) {
+			_, err := newCalverScheme(calverFormat, DEFAULT_RELEASES, time.Now)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestCalverParseAndFormat(t *testing.T) {
+	var tests = []struct {
+		calverFormat, input, expected string
+	}{
+		{"YYYY.0M.MICRO", "2026.01.3", "2026.01.3"},
+		{"YYYY.MM.MICRO", "2026.10.3", "2026.10.3"},
+		{"YY.0M", "26.04", "26.04"},
+		{"YYYY.0M.0D.MICRO", "2026.10.08.12", "2026.10.08.12"},
+		{"YYYY.WW", "2026.7", "2026.7"},
+		{"YYYY.0M.MICRO", "2026.10.3-beta.1", "2026.10.3-beta.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			s := newTestCalverScheme(t, tt.calverFormat, 2026, time.October, 18)
+			v := parseCalver(t, s, tt.input)
+			assert.Equal(t, tt.expected, v.toString())
+		})
+	}
+}
+
+func TestCalverBumpToToday(t *testing.T) {
+	var tests = []struct {
+		calverFormat, version, expected string
+		year                            int
+		month                           time.Month
+		day                             int
+	}{
+		{"YYYY.0M.MICRO", "2026.09.3", "2026.10.0", 2026, time.October, 18},
+		{"YYYY.0M.MICRO", "2026.10.3", "2026.10.4", 2026, time.October, 18},
+		{"YYYY.0M.MICRO", "2026.10.3-rc.2", "2026.10.4", 2026, time.October, 18},
+		{"YY.0M", "26.09", "26.10", 2026, time.October, 18},
+		{"YY.MM.MICRO", "25.12.7", "26.1.0", 2026, time.January, 2},
+		{"YYYY.0W", "2025.52", "2026.01", 2025, time.December, 30},
+		{"YYYY.0M.0D", "2026.10.17", "2026.10.18", 2026, time.October, 18},
+	}
+
+	for _, tt := range tests {
+		testname := fmt.Sprintf("%s on %d-%d-%d", tt.version, tt.year, tt.month, tt.day)
+		t.Run(testname, func(t *testing.T) {
+			s := newTestCalverScheme(t, tt.calverFormat, tt.year, tt.month, tt.day)
+			assertNewVersion(t, parseCalver(t, s, tt.version), "calver", "", tt.expected)
+		})
+	}
+}
+
+func TestCalverBump(t *testing.T) {
+	s := newTestCalverScheme(t, "YYYY.0M.MICRO", 2026, time.October, 18)
+	v := parseCalver(t, s, "2026.09.3")
+
+	assertNewVersion(t, v, "patch", "", "2026.09.4")
+	assertNewVersion(t, v, "", "beta", "2026.09.3-beta.0")
+	assertNewVersion(t, v, "calver", "rc", "2026.10.0-rc.0")
+}
+
+func TestCalverCompare(t *testing.T) {
+	s := newTestCalverScheme(t, "YYYY.0M.MICRO", 2026, time.October, 18)
+
+	assert.Equal(t, -1, parseCalver(t, s, "2026.09.3").compare(parseCalver(t, s, "2026.10.0")))
+	assert.Equal(t, -1, parseCalver(t, s, "2026.10.0-rc.1").compare(parseCalver(t, s, "2026.10.0")))
+	assert.True(t, parseCalver(t, s, "2026.10.0").equals(parseCalver(t, s, "2026.10.00")))
+}
+
+func TestCalverVersionFinder(t *testing.T) {
+	s := newTestCalverScheme(t, "YYYY.0M.MICRO", 2026, time.October, 18)
+	finder := NewVersionFinder(s)
+
+	v, found := finder.Find(`VERSION = "2026.10.3"`)
+	assert.True(t, found)
+	assert.Equal(t, "2026.10.3", v.toString())
+
+	_, found = finder.Find(`version = "1.2.0"`)
+	assert.False(t, found)
+}
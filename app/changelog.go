@@ -0,0 +1,244 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Where the entries of a new changelog section come from: the section under
+// the Unreleased heading, or the commits since the last version tag.
+const (
+	CHANGELOG_FROM_UNRELEASED = "unreleased"
+	CHANGELOG_FROM_COMMITS    = "commits"
+)
+
+var CHANGELOG_SOURCES = []string{"", CHANGELOG_FROM_UNRELEASED, CHANGELOG_FROM_COMMITS}
+
+// DEFAULT_CHANGELOG_HEADING is the Keep a Changelog heading of a release.
+const DEFAULT_CHANGELOG_HEADING = "## [{version}] - {date}"
+
+const CHANGELOG_UNRELEASED = "unreleased"
+
+// Placeholders of the changelog heading, on top of {version}.
+const (
+	DATE_PLACEHOLDER  = "{date}"
+	YEAR_PLACEHOLDER  = "{year}"
+	MONTH_PLACEHOLDER = "{month}"
+	DAY_PLACEHOLDER   = "{day}"
+)
+
+// e.g. `## [0.3.0] - 2025-07-01` or `##### v0.3.0 (July 2025)`
+var MARKDOWN_HEADING_RX = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// changelogNow is swapped out in tests for a fixed date.
+var changelogNow = time.Now
+
+// ChangelogRelease is the section a plan adds to the changelog File for the
+// new version: the Heading and the Entries under it. With FromUnreleased the
+// entries are moved from the Unreleased section, otherwise they are new.
+type ChangelogRelease struct {
+	File           string
+	Heading        string
+	Entries        []string
+	FromUnreleased bool
+	err            error
+}
+
+// Err is why the section can not be added to the changelog, e.g. its
+// Unreleased section is empty. The plan can still be shown, but Apply fails
+// with it.
+func (r *ChangelogRelease) Err() error {
+	return r.err
+}
+
+// markdownHeading reads the level and text of a markdown heading line, with
+// a level of 0 for any other line.
+func markdownHeading(line string) (int, string) {
+	match := MARKDOWN_HEADING_RX.FindStringSubmatch(line)
+	if match == nil {
+		return 0, ""
+	}
+	return len(match[1]), match[2]
+}
+
+// isUnreleasedHeading is true for `Unreleased` and `[Unreleased]` headings.
+func isUnreleasedHeading(text string) bool {
+	return strings.EqualFold(strings.Trim(text, "[] "), CHANGELOG_UNRELEASED)
+}
+
+// sectionEnd is the index of the line after the section whose heading is at
+// index: the next heading of the same or a higher level, or the end.
+func sectionEnd(lines []string, index int) int {
+	level, _ := markdownHeading(lines[index])
+	for end := index + 1; end < len(lines); end++ {
+		if next, _ := markdownHeading(lines[end]); next > 0 && next <= level {
+			return end
+		}
+	}
+	return len(lines)
+}
+
+// unreleasedSection finds the Unreleased heading of a changelog, returning
+// -1 when there is none, and the end of its section.
+func unreleasedSection(lines []string) (int, int) {
+	for index, line := range lines {
+		if level, text := markdownHeading(line); level > 0 && isUnreleasedHeading(text) {
+			return index, sectionEnd(lines, index)
+		}
+	}
+	return -1, -1
+}
+
+// changelogHeading fills in the placeholders of the heading template.
+func changelogHeading(template string, version string, now time.Time) string {
+	return strings.NewReplacer(
+		VERSION_PLACEHOLDER, version,
+		DATE_PLACEHOLDER, now.Format("2006-01-02"),
+		YEAR_PLACEHOLDER, strconv.Itoa(now.Year()),
+		MONTH_PLACEHOLDER, now.Month().String(),
+		DAY_PLACEHOLDER, strconv.Itoa(now.Day()),
+	).Replace(template)
+}
+
+// changelogInsertion works out where the release's section goes in the
+// lines of a changelog, and its lines.
+//
+// From the Unreleased section, only the new heading is added, right after the
+// Unreleased heading, so that the content of the section moves under it.
+// Otherwise the new section goes before the first release heading of the
+// same level after any Unreleased section, or at the end.
+func changelogInsertion(lines []string, release *ChangelogRelease) (int, []string, error) {
+	start, end := unreleasedSection(lines)
+	if release.FromUnreleased {
+		if start == -1 {
+			return 0, nil, &ConfigError{Err: fmt.Errorf("%s has no Unreleased section", release.File)}
+		}
+		return start + 1, []string{"", release.Heading}, nil
+	}
+
+	level, _ := markdownHeading(release.Heading)
+	if start == -1 {
+		end = 0
+	}
+	count := lineCount(lines)
+	index := count
+	for at := end; at < count; at++ {
+		if next, text := markdownHeading(lines[at]); next == level && !isUnreleasedHeading(text) {
+			index = at
+			break
+		}
+	}
+
+	section := append([]string{release.Heading}, release.Entries...)
+	if index < count {
+		section = append(section, "")
+	} else if index > 0 && strings.TrimSpace(lines[index-1]) != "" {
+		section = append([]string{""}, section...)
+	}
+	return index, section, nil
+}
+
+func readChangelog(dir string, file string) (*textFile, error) {
+	content, err := os.ReadFile(projectPath(dir, file))
+	if err != nil {
+		return nil, err
+	}
+	return parseTextFile(content), nil
+}
+
+// changelogRelease works out the changelog section for the plan's version.
+// A section with nothing to add is still worked out, with the reason in its
+// err, so that a plan can be shown before the changelog is ready.
+func (p *Project) changelogRelease(plan *Plan) (*ChangelogRelease, error) {
+	template := p.config.changelogHeading
+	if template == "" {
+		template = DEFAULT_CHANGELOG_HEADING
+	}
+	release := ChangelogRelease{
+		File:           p.config.changelog,
+		Heading:        changelogHeading(template, plan.Next.format(plan.Bump.Format), changelogNow()),
+		FromUnreleased: p.config.changelogFrom != CHANGELOG_FROM_COMMITS,
+	}
+
+	if release.FromUnreleased {
+		changelog, err := readChangelog(p.dir, release.File)
+		if err != nil {
+			return nil, err
+		}
+		lines := changelog.lines
+		start, end := unreleasedSection(lines)
+		if start == -1 {
+			release.err = &ConfigError{Err: fmt.Errorf("%s has no Unreleased section", release.File)}
+			return &release, nil
+		}
+		for _, line := range lines[start+1 : end] {
+			if strings.TrimSpace(line) != "" {
+				release.Entries = append(release.Entries, line)
+			}
+		}
+		if len(release.Entries) == 0 {
+			release.err = &ConfigError{Err: fmt.Errorf("the Unreleased section of %s is empty", release.File)}
+		}
+		return &release, nil
+	}
+
+	tag, err := p.lastVersionTag()
+	if err != nil {
+		return nil, err
+	}
+	commits, err := gitCommits(p.dir, tag)
+	if err != nil {
+		return nil, err
+	}
+	for _, commit := range commits {
+		release.Entries = append(release.Entries, "- "+commit.Subject)
+	}
+	if len(release.Entries) == 0 {
+		release.err = &ConfigError{Err: errors.New("there are no commits since the last version tag for the changelog")}
+	}
+	return &release, nil
+}
+
+// prepareChangelogUpdate adds the release to the changelog, as part of the
+// updates of the versioned files. A changelog that is also a versioned file
+// gets both changes.
+func prepareChangelogUpdate(dir string, updates []*fileUpdate, release *ChangelogRelease) ([]*fileUpdate, error) {
+	filePath, err := filepath.EvalSymlinks(projectPath(dir, release.File))
+	if err != nil {
+		return nil, err
+	}
+
+	var update *fileUpdate
+	for _, existing := range updates {
+		if existing.path == filePath {
+			update = existing
+		}
+	}
+	if update == nil {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		update = &fileUpdate{path: filePath, info: info, original: content, content: content}
+		updates = append(updates, update)
+	}
+
+	changelog := parseTextFile(update.content)
+	index, lines, err := changelogInsertion(changelog.lines, release)
+	if err != nil {
+		return nil, err
+	}
+	changelog.insertLines(index, lines)
+	update.content = changelog.bytes()
+	return updates, nil
+}
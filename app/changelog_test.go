@@ -0,0 +1,204 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		text  string
+	}{
+		{"## [Unreleased]", 2, "[Unreleased]"},
+		{"##### v0.3.0 (July 2025)", 5, "v0.3.0 (July 2025)"},
+		{"# Changelog #", 1, "Changelog"},
+		{"- added --echo", 0, ""},
+		{"#hashtag", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			level, text := markdownHeading(tt.line)
+			assert.Equal(t, tt.level, level)
+			assert.Equal(t, tt.text, text)
+		})
+	}
+}
+
+func TestChangelogHeading(t *testing.T) {
+	now := time.Date(2025, time.July, 4, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "## [0.3.0] - 2025-07-04", changelogHeading(DEFAULT_CHANGELOG_HEADING, "0.3.0", now))
+	assert.Equal(t, "##### v0.3.0 (July 2025)", changelogHeading("##### v{version} ({month} {year})", "0.3.0", now))
+	assert.Equal(t, "### 0.3.0, 4 July", changelogHeading("### {version}, {day} {month}", "0.3.0", now))
+}
+
+func TestChangelogInsertion(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		release  ChangelogRelease
+		expected string
+	}{
+		{
+			"unreleased",
+			"# Changelog\n\n## [Unreleased]\n- added --auto\n\n## [0.3.0] - 2025-07-04\n- added --echo\n",
+			ChangelogRelease{Heading: "## [0.4.0] - 2026-10-18", FromUnreleased: true},
+			"# Changelog\n\n## [Unreleased]\n\n## [0.4.0] - 2026-10-18\n- added --auto\n\n## [0.3.0] - 2025-07-04\n- added --echo\n",
+		},
+		{
+			"commits after unreleased",
+			"# Changelog\n\n## [Unreleased]\n- added --auto\n\n## [0.3.0] - 2025-07-04\n- added --echo\n",
+			ChangelogRelease{Heading: "## [0.4.0] - 2026-10-18", Entries: []string{"- fix: b", "- feat: a"}},
+			"# Changelog\n\n## [Unreleased]\n- added --auto\n\n## [0.4.0] - 2026-10-18\n- fix: b\n- feat: a\n\n## [0.3.0] - 2025-07-04\n- added --echo\n",
+		},
+		{
+			"commits before the first release",
+			"### RELEASES\n\n\n##### v0.3.0 (July 2025)\n- added --echo\n",
+			ChangelogRelease{Heading: "##### v0.4.0 (October 2026)", Entries: []string{"- feat: a"}},
+			"### RELEASES\n\n\n##### v0.4.0 (October 2026)\n- feat: a\n\n##### v0.3.0 (July 2025)\n- added --echo\n",
+		},
+		{
+			"commits at the end",
+			"# Changelog",
+			ChangelogRelease{Heading: "## [0.1.0] - 2026-10-18", Entries: []string{"- feat: a"}},
+			"# Changelog\n\n## [0.1.0] - 2026-10-18\n- feat: a",
+		},
+		{
+			"crlf",
+			"# Changelog\r\n\r\n## Unreleased\r\n- added --auto\r\n",
+			ChangelogRelease{Heading: "## 0.4.0", FromUnreleased: true},
+			"# Changelog\r\n\r\n## Unreleased\r\n\r\n## 0.4.0\r\n- added --auto\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changelog := parseTextFile([]byte(tt.content))
+			index, lines, err := changelogInsertion(changelog.lines, &tt.release)
+			assert.Nil(t, err)
+			changelog.insertLines(index, lines)
+			assert.Equal(t, tt.expected, string(changelog.bytes()))
+		})
+	}
+
+	_, _, err := changelogInsertion([]string{"# Changelog", ""}, &ChangelogRelease{File: "CHANGELOG.md", FromUnreleased: true})
+	assert.EqualError(t, err, "CHANGELOG.md has no Unreleased section")
+}
+
+func (suite *ProjectTestSuite) writeChangelogProject(changelog string) {
+	changelogNow = func() time.Time { return time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC) }
+	suite.T().Cleanup(func() { changelogNow = time.Now })
+
+	suite.writeFile(".dover", `[dover]
+version_format = "000"
+versioned_files = [
+	"coding.go",
+	"HISTORY.md",
+]
+changelog = "HISTORY.md"
+changelog_heading = "##### v{version} ({month} {year})"
+`)
+	suite.writeFile("coding.go", "package coding\n\nconst VERSION = \"0.3.0\"\n")
+	suite.writeFile("HISTORY.md", changelog)
+}
+
+func (suite *ProjectTestSuite) TestChangelogPlan() {
+	suite.writeChangelogProject("version 0.3.0\n\n##### Unreleased\n- added --auto\n- added notes\n\n##### v0.3.0 (July 2025)\n- added --echo\n")
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	plan, err := project.Plan(Bump{Part: "minor"})
+	suite.Nil(err)
+	suite.Equal(&ChangelogRelease{
+		File:           "HISTORY.md",
+		Heading:        "##### v0.4.0 (October 2026)",
+		Entries:        []string{"- added --auto", "- added notes"},
+		FromUnreleased: true,
+	}, plan.Changelog)
+
+	// the changelog is also a versioned file and gets both changes
+	suite.Nil(project.Apply(plan))
+	suite.Equal("version 0.4.0\n\n##### Unreleased\n\n##### v0.4.0 (October 2026)\n- added --auto\n- added notes\n\n##### v0.3.0 (July 2025)\n- added --echo\n", suite.readFile("HISTORY.md"))
+	suite.Equal([]string{"HISTORY.md", "coding.go"}, changedFiles(plan))
+}
+
+func (suite *ProjectTestSuite) TestChangelogPlanErrors() {
+	history := "version 0.3.0\n\n##### Unreleased\n\n##### v0.3.0 (July 2025)\n- added --echo\n"
+	suite.writeChangelogProject(history)
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	// the plan can be shown, but not applied
+	plan, err := project.Plan(Bump{Part: "minor"})
+	suite.Nil(err)
+	suite.Equal("the Unreleased section of HISTORY.md is empty", fmt.Sprint(plan.Changelog.Err()))
+	err = project.Apply(plan)
+	suite.Equal("the Unreleased section of HISTORY.md is empty", fmt.Sprint(err))
+	suite.Equal(EXIT_CONFIG_ERROR, exitCode(err))
+	suite.Equal(history, suite.readFile("HISTORY.md"))
+	suite.Equal("package coding\n\nconst VERSION = \"0.3.0\"\n", suite.readFile("coding.go"))
+
+	suite.writeFile("HISTORY.md", "version 0.3.0\n")
+	plan, err = project.Plan(Bump{Part: "minor"})
+	suite.Nil(err)
+	err = project.Apply(plan)
+	suite.Equal("HISTORY.md has no Unreleased section", fmt.Sprint(err))
+	suite.Equal(EXIT_CONFIG_ERROR, exitCode(err))
+}
+
+func (suite *ProjectTestSuite) TestChangelogRollback() {
+	suite.writeChangelogProject("##### Unreleased\n- added --auto\n")
+	suite.writeFile(".dover", `[dover]
+version_format = "000"
+versioned_files = ["coding.go"]
+changelog = "HISTORY.md"
+`)
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+	plan, err := project.Plan(Bump{Part: "minor"})
+	suite.Nil(err)
+
+	// the changelog is written along with the versioned files, all or nothing
+	renameFile = func(from string, to string) error {
+		if strings.HasSuffix(to, "HISTORY.md") {
+			return errors.New("disk full")
+		}
+		return os.Rename(from, to)
+	}
+	defer func() { renameFile = os.Rename }()
+
+	err = project.Apply(plan)
+	suite.Equal("disk full, no files have been changed", fmt.Sprint(err))
+	suite.Equal("package coding\n\nconst VERSION = \"0.3.0\"\n", suite.readFile("coding.go"))
+	suite.Equal("##### Unreleased\n- added --auto\n", suite.readFile("HISTORY.md"))
+}
+
+func (suite *GitTestSuite) TestChangelogFromCommits() {
+	suite.writeFile("api/CHANGELOG.md", "# Changelog\n\n## [1.2.0] - 2026-01-01\n- feat: first\n")
+	suite.writeFile("api/.dover", `[dover]
+version_format = "000"
+versioned_files = ["main.go", "version.txt"]
+changelog = "CHANGELOG.md"
+changelog_from = "commits"
+`)
+	suite.commit("chore: keep a changelog")
+	suite.git("tag", "v1.2.0")
+	suite.commit("feat(api): add login")
+	suite.commit("fix: handle empty files")
+
+	project, plan := suite.plan("minor")
+	suite.Equal([]string{"- fix: handle empty files", "- feat(api): add login"}, plan.Changelog.Entries)
+	suite.False(plan.Changelog.FromUnreleased)
+
+	suite.Nil(project.Apply(plan))
+	suite.Nil(project.Commit(plan))
+	suite.Equal("api/CHANGELOG.md\napi/main.go\napi/version.txt", suite.git("show", "--name-only", "--format=", "HEAD"))
+	suite.Contains(suite.git("show", "HEAD:api/CHANGELOG.md"), "- feat: first")
+	suite.Equal("", suite.git("status", "--porcelain"))
+}
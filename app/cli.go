@@ -1,6 +1,7 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -19,34 +20,49 @@ func selectFormat(args ExecutionArgs, cfg ConfigValues) string {
 	return cfg.format
 }
 
-func filterFlags(args map[string]any, flags []string) string {
+// filterFlags returns the one of flags that is given, a switch that is set or
+// an option with a value, and fails when more than one is.
+func filterFlags(args map[string]any, flags []string) (string, error) {
 	activeFlags := []string{}
 	for key, value := range args {
 		key = strings.TrimLeft(key, "-")
 		for _, flag := range flags {
-			if key == flag && value == true {
+			if text, isText := value.(string); key == flag && (value == true || isText && text != "") {
 				activeFlags = append(activeFlags, key)
 			}
 		}
 	}
 	switch len(activeFlags) {
 	case 1:
-		return activeFlags[0]
+		return activeFlags[0], nil
 	case 0:
-		return ""
+		return "", nil
 	default:
-		panic("Active flags can only be 1 or 0.")
+		return "", &UsageError{Err: fmt.Errorf("only one of --%s can be used", strings.Join(activeFlags, ", --"))}
 	}
 }
 
 type ExecutionArgs struct {
-	initialize bool
-	echo       bool
-	increment  bool
-	format     string
-	verbose    bool
-	part       string
-	preRelease string
+	initialize     bool
+	listComponents bool
+	component      string
+	sync           bool
+	syncTo         string
+	syncStrategy   string
+	notes          bool
+	notesVersion   string
+	echo           bool
+	increment      bool
+	commit         bool
+	tag            bool
+	allowDirty     bool
+	format         string
+	verbose        bool
+	output         string
+	configFile     string
+	root           string
+	part           string
+	preRelease     string
 }
 
 type ColorizedWriter struct {
@@ -80,6 +96,9 @@ func (u *Usage) writeAppPrefix(b *strings.Builder, writer *ColorizedWriter, firs
 }
 
 func (u *Usage) writeCommandUsage(b *strings.Builder, writer *ColorizedWriter, cmdWidth int, cmd string, use string) {
+	if cmd != "" && use != "" {
+		use = " " + use
+	}
 	fmt.Fprintf(b, " %-0*s%s\n", cmdWidth, cmd, use)
 }
 
@@ -91,7 +110,11 @@ func (u *Usage) buildUsage(b *strings.Builder, writer *ColorizedWriter) {
 		if len(usageList) > 0 {
 			for index, use := range usageList {
 				u.writeAppPrefix(b, writer, index == 0)
-				u.writeCommandUsage(b, writer, len(cmd), cmd, use)
+				if index == 0 {
+					u.writeCommandUsage(b, writer, len(cmd), cmd, use)
+				} else {
+					u.writeCommandUsage(b, writer, len(cmd), strings.Repeat(" ", len(cmd)), use)
+				}
 			}
 		} else {
 			u.writeAppPrefix(b, writer, true)
@@ -169,11 +192,18 @@ https://github.com/markgemmill/dover`,
 		options: orderedmap.NewOrderedMap[string, string](),
 	}
 	usageBuilder.addUsage("", []string{
-		"[--increment | --echo] [--format=<fmt>] [--verbose]",
-		"[--major | --minor | --patch | --build] ",
-		"[--pre-release | --dev | --alpha | --beta | --rc | --release]",
+		"[--increment | --echo] [--format=<fmt>] [--verbose] [--output=<fmt>]",
+		"[--config=<path>] [--root=<dir>] [--component=<name>] [--commit [--tag]] [--allow-dirty]",
+		"[--major | --minor | --patch | --build | --calver | --auto] ",
+		"[--pre-release | --pre=<label> | --dev | --alpha | --beta | --rc | --post | --release]",
+	})
+	usageBuilder.addUsage("init", []string{"[--root=<dir>]"})
+	usageBuilder.addUsage("sync", []string{
+		"[--to=<version> | --highest | --majority] [--increment] [--format=<fmt>] [--verbose]",
+		"[--output=<fmt>] [--config=<path>] [--root=<dir>] [--component=<name>] [--allow-dirty]",
 	})
-	usageBuilder.addUsage("init", []string{})
+	usageBuilder.addUsage("notes", []string{"[<version>] [--format=<fmt>] [--output=<fmt>] [--config=<path>] [--root=<dir>] [--component=<name>]"})
+	usageBuilder.addUsage("components", []string{"[--format=<fmt>] [--output=<fmt>] [--config=<path>] [--root=<dir>]"})
 
 	usageBuilder.addOption("-i --increment", "Apply the increment.")
 	usageBuilder.addOption("-e --echo", "Display future version.")
@@ -181,14 +211,28 @@ https://github.com/markgemmill/dover`,
 	usageBuilder.addOption("-M --major", "Update major version segment.")
 	usageBuilder.addOption("-m --minor", "Update minor version segment.")
 	usageBuilder.addOption("-p --patch", "Update patch version segment.")
+	usageBuilder.addOption("-C --calver", "Roll a calver version to today's date.")
+	usageBuilder.addOption("--auto", "Pick the segment from the conventional commits since the last tag.")
 	usageBuilder.addOption("-P --pre-release", "Update to next pre-release.")
+	usageBuilder.addOption("--pre=<label>", "Update to the named pre-release or bump its build.")
 	usageBuilder.addOption("-d --dev", "Update dev version segment or bump dev build.")
 	usageBuilder.addOption("-a --alpha", "Update alpha pre-release segment or bump alpha build.")
 	usageBuilder.addOption("-b --beta", "Update beta pre-release segment or bump beta build.")
 	usageBuilder.addOption("-r --rc", "Update release candidate segment or bump rc build.")
+	usageBuilder.addOption("--post", "Update post release segment or bump post build (pep440).")
 	usageBuilder.addOption("-B --build", "Update the pre-release build number.")
 	usageBuilder.addOption("-R --release", "Clear pre-release version.")
 	usageBuilder.addOption("-v --verbose", "Display details when incrementing.")
+	usageBuilder.addOption("-o --output=<fmt>", "Output as text, json or yaml [default: text].")
+	usageBuilder.addOption("--config=<path>", "Read the dover configuration from this file.")
+	usageBuilder.addOption("--root=<dir>", "Project directory, instead of searching up from here.")
+	usageBuilder.addOption("--component=<name>", "Use the version of the named component.")
+	usageBuilder.addOption("--commit", "Commit the updated files to git.")
+	usageBuilder.addOption("--tag", "Tag the commit with the new version.")
+	usageBuilder.addOption("--allow-dirty", "Update the files even with uncommitted changes.")
+	usageBuilder.addOption("--to=<version>", "Sync the versioned files to this version.")
+	usageBuilder.addOption("--highest", "Sync the versioned files to the highest version found.")
+	usageBuilder.addOption("--majority", "Sync the versioned files to the version most of them have.")
 	usageBuilder.addOption("-h --help", "Display this help message.")
 	usageBuilder.addOption("--version", "Display dover version.")
 
@@ -216,7 +260,7 @@ func ParseCommandline() docopt.Opts {
 
 		if err != nil {
 			fmt.Fprintln(os.Stderr, output)
-			os.Exit(1)
+			os.Exit(EXIT_USAGE)
 
 		} else {
 			fmt.Println(output)
@@ -234,58 +278,173 @@ func ParseCommandline() docopt.Opts {
 	return arguments
 }
 
-func compileArguments(opts docopt.Opts) ExecutionArgs {
+func compileArguments(opts docopt.Opts) (ExecutionArgs, error) {
 	initialize, _ := opts.Bool("init")
+	listComponents, _ := opts.Bool("components")
+	component, _ := opts.String("--component")
+	sync, _ := opts.Bool("sync")
+	syncTo, _ := opts.String("--to")
+	syncStrategy, err := filterFlags(opts, []string{SYNC_HIGHEST, SYNC_MAJORITY})
+	if err != nil {
+		return ExecutionArgs{}, err
+	}
+	notes, _ := opts.Bool("notes")
+	notesVersion, _ := opts.String("<version>")
 	increment, _ := opts.Bool("--increment")
 	echo, _ := opts.Bool("--echo")
+	commit, _ := opts.Bool("--commit")
+	tag, _ := opts.Bool("--tag")
+	allowDirty, _ := opts.Bool("--allow-dirty")
+	if tag && !commit {
+		return ExecutionArgs{}, &UsageError{Err: errors.New("--tag needs --commit, the tag is for the version commit")}
+	}
 	format, _ := opts.String("--format")
 	verbose, _ := opts.Bool("--verbose")
+	output, _ := opts.String("--output")
+	configFile, _ := opts.String("--config")
+	root, _ := opts.String("--root")
+	if output == "" {
+		output = OUTPUT_TEXT
+	}
+	if IndexOf(&OUTPUT_FORMATS, output) == -1 {
+		return ExecutionArgs{}, &UsageError{Err: fmt.Errorf("unknown output format: %s", output)}
+	}
+
+	part, err := filterFlags(opts, []string{"major", "minor", "patch", "build", "calver", AUTO_PART})
+	if err != nil {
+		return ExecutionArgs{}, err
+	}
+
+	preRelease, err := filterFlags(opts, []string{"pre-release", "pre", "dev", "alpha", "beta", "rc", "post", "release"})
+	if err != nil {
+		return ExecutionArgs{}, err
+	}
+	if preRelease == "pre" {
+		preRelease, _ = opts.String("--pre")
+	}
 
 	args := ExecutionArgs{
-		initialize: initialize,
-		increment:  increment,
-		echo:       echo,
-		format:     format,
-		verbose:    verbose,
-		part:       filterFlags(opts, []string{"major", "minor", "patch", "build"}),
-		preRelease: filterFlags(opts, []string{"pre-release", "dev", "alpha", "beta", "rc", "release"}),
-	}
-	return args
+		initialize:     initialize,
+		listComponents: listComponents,
+		component:      component,
+		sync:           sync,
+		syncTo:         syncTo,
+		syncStrategy:   syncStrategy,
+		notes:          notes,
+		notesVersion:   notesVersion,
+		increment:      increment,
+		commit:         commit,
+		tag:            tag,
+		allowDirty:     allowDirty,
+		echo:           echo,
+		format:         format,
+		verbose:        verbose,
+		output:         output,
+		configFile:     configFile,
+		root:           root,
+		part:           part,
+		preRelease:     preRelease,
+	}
+	return args, nil
 }
 
-func Execute() {
-	args := compileArguments(ParseCommandline())
+// loadProject loads the project given by the --config and --root options,
+// or else the closest one from the working directory up.
+func loadProject(args ExecutionArgs) (*Project, error) {
+	if args.configFile != "" {
+		return LoadProjectConfig(args.configFile, args.root)
+	}
+	if args.root != "" {
+		return LoadProject(args.root)
+	}
+	return FindProject(".")
+}
 
-	c.NoColor = false
+// selectComponent narrows the project down to the --component, which has to
+// be given when the project is made up of components alone.
+func selectComponent(args ExecutionArgs, project *Project) (*Project, error) {
+	if args.component != "" {
+		return project.Component(args.component)
+	}
+	if len(project.config.files) == 0 && len(project.config.components) > 0 {
+		return nil, &ConfigError{Err: fmt.Errorf("the project is made up of components, pick one with --component: %s", strings.Join(project.Components(), ", "))}
+	}
+	return project, nil
+}
 
-	cfg, err := configValues()
-	ExitOnError(err)
+// run carries out the command and returns any error for Execute to report.
+func run(args ExecutionArgs) error {
+	if args.initialize {
+		return initialize(args.root)
+	}
 
-	args.format = selectFormat(args, cfg)
-	allMatches := getAllVersionStringMatches(cfg.files)
+	project, err := loadProject(args)
+	if err != nil {
+		return err
+	}
+	warnShadowedConfig(project)
 
-	if args.initialize {
-		initialize()
-		return
+	if args.listComponents {
+		return displayComponents(args, project)
+	}
+
+	project, err = selectComponent(args, project)
+	if err != nil {
+		return err
+	}
+
+	args.format = selectFormat(args, project.config)
+	_, err = NewVersionFormater(args.format)
+	if err != nil {
+		return err
+	}
+
+	if args.sync {
+		return syncVersions(args, project)
+	}
+
+	if args.notes {
+		return displayNotes(args, project)
+	}
+
+	if args.part == AUTO_PART {
+		auto, err := project.AutoBump()
+		if err != nil {
+			return err
+		}
+		args.part = auto.Part
+		if args.verbose && args.output == OUTPUT_TEXT {
+			explainAutoBump(auto)
+		}
 	}
 
 	if args.echo {
-		displayFutureVersion(args, allMatches)
-		return
+		return displayFutureVersion(args, project)
 	}
 
 	if !args.increment && args.part == "" && args.preRelease == "" {
-		displayCurrentVersion(args, allMatches)
-		return
+		return displayCurrentVersion(args, project)
 	}
 
 	if !args.increment && (args.part != "" || args.preRelease != "") {
-		displayNextVersion(args, allMatches)
-		return
+		return displayNextVersion(args, project)
 	}
 
 	if args.increment && (args.part != "" || args.preRelease != "") {
-		applyNextVersion(args, allMatches)
-		return
+		return applyNextVersion(args, project)
+	}
+
+	return nil
+}
+
+func Execute() {
+	c.NoColor = false
+
+	args, err := compileArguments(ParseCommandline())
+	if err == nil {
+		err = run(args)
+	}
+	if err != nil {
+		os.Exit(reportError(args, err))
 	}
 }
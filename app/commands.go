@@ -1,9 +1,13 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	c "github.com/fatih/color"
 	"github.com/logrusorgru/aurora"
 )
 
@@ -13,103 +17,414 @@ func printCurrentVersions(matches *[]*VersionMatch, format string) {
 
 	for _, match := range *matches {
 		fmt.Printf(
-			"%-0*s: %0*d  %-0*s\n",
+			"%-0*s: %-0*s  %-0*s\n",
 			fileW,
 			aurora.Yellow(match.file),
 			lineW,
-			aurora.Blue(match.line),
+			aurora.Blue(match.position()),
 			versW,
 			aurora.BrightWhite(match.version.format(format)).Bold(),
 		)
 	}
 }
 
-func printVersionChanges(matches *[]*VersionMatch, part string, release string, format string, updated bool) {
+func printVersionChanges(plan *Plan, updated bool) {
+	matches := []*VersionMatch{}
+	for _, change := range plan.Changes {
+		matches = append(matches, change.Match)
+	}
+
 	var fileW, lineW, versW int
-	fileW, lineW, versW = getMaxColumnWidths(matches, format)
+	fileW, lineW, versW = getMaxColumnWidths(&matches, plan.Bump.Format)
 
 	_update := ""
 	if updated {
 		_update = "updated "
 	}
 
-	for _, match := range *matches {
-		nv := match.version.bump(part, release)
+	for _, change := range plan.Changes {
 		fmt.Printf(
-			"%-0*s: %0*d %s%-0*s -> %s\n",
+			"%-0*s: %-0*s %s%-0*s -> %s\n",
 			fileW,
-			aurora.Yellow(match.file),
+			aurora.Yellow(change.Match.file),
 			lineW,
-			aurora.Blue(match.line),
+			aurora.Blue(change.Match.position()),
 			_update,
 			versW,
-			aurora.BrightWhite(match.version.format(format)).Bold(),
-			aurora.BrightWhite(nv.format(format)),
+			aurora.BrightWhite(change.Old).Bold(),
+			aurora.BrightWhite(change.New),
 		)
 	}
 }
 
-func displayInconsistentVersionMatch(args ExecutionArgs, matches *[]*VersionMatch) {
-	if !assertVersionMatchConsistency(matches) {
+// printChangelogRelease shows the section the plan adds to the changelog,
+// or warns that it can not be added yet.
+func printChangelogRelease(plan *Plan, updated bool) {
+	if plan.Changelog == nil {
+		return
+	}
+	if plan.Changelog.err != nil {
+		fmt.Fprintln(os.Stderr, aurora.Yellow(fmt.Sprintf(
+			"%s, the changelog can not be updated and the increment will fail.",
+			plan.Changelog.err,
+		)))
+		return
+	}
+	action := "will add"
+	if updated {
+		action = "added"
+	}
+	entries := fmt.Sprintf("%d entries", len(plan.Changelog.Entries))
+	if len(plan.Changelog.Entries) == 1 {
+		entries = "1 entry"
+	}
+	fmt.Printf(
+		"%s: %s %s (%s)\n",
+		aurora.Yellow(plan.Changelog.File),
+		action,
+		aurora.BrightWhite(plan.Changelog.Heading).Bold(),
+		entries,
+	)
+}
+
+// warnShadowedConfig tells the user about config files with a dover section
+// that is not used, as a config file before them has one too.
+func warnShadowedConfig(project *Project) {
+	cfg := project.config
+	if len(cfg.shadowed) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, aurora.Yellow(fmt.Sprintf(
+		"Using the dover config in %s, the dover config in %s is ignored.",
+		cfg.source,
+		strings.Join(cfg.shadowed, ", "),
+	)))
+}
+
+// reportError prints err and returns the exit code for it. Inconsistent
+// versions are listed so the files can be fixed.
+func reportError(args ExecutionArgs, err error) int {
+	if args.output == OUTPUT_JSON || args.output == OUTPUT_YAML {
+		writeDocument(os.Stdout, args.output, newErrorDocument(commandName(args), err, args.format))
+		return exitCode(err)
+	}
+
+	var inconsistentError *InconsistentVersionError
+	if errors.As(err, &inconsistentError) {
 		if args.increment {
 			fmt.Print(aurora.BrightMagenta("No files have been changed!\n"))
 		}
 		fmt.Print(aurora.BrightMagenta("\nVersions do not match across all files.\n"))
-		printCurrentVersions(matches, args.format)
-		os.Exit(1)
+		printCurrentVersions(&inconsistentError.Matches, args.format)
+		if inconsistentError.Reason != "" {
+			fmt.Print(aurora.BrightMagenta(fmt.Sprintf("\n%s.\n", inconsistentError.Reason)))
+		} else {
+			fmt.Print(aurora.BrightMagenta("\nUse `dover sync` to bring them back in line.\n"))
+		}
+		return exitCode(err)
 	}
+
+	fmt.Fprintf(os.Stderr, "%s\n", c.RedString(err.Error()))
+	return exitCode(err)
 }
 
-func displayCurrentVersion(args ExecutionArgs, matches *[]*VersionMatch) {
-	displayInconsistentVersionMatch(args, matches)
+// commandName names what the arguments ask dover to do: show, echo, plan,
+// apply, sync, notes or init.
+func commandName(args ExecutionArgs) string {
+	switch {
+	case args.initialize:
+		return "init"
+	case args.listComponents:
+		return "components"
+	case args.sync:
+		return "sync"
+	case args.notes:
+		return "notes"
+	case args.echo:
+		return "echo"
+	case args.part == "" && args.preRelease == "":
+		return "show"
+	case args.increment:
+		return "apply"
+	}
+	return "plan"
+}
+
+func displayCurrentVersion(args ExecutionArgs, project *Project) error {
+	current, err := project.Current()
+	if err != nil {
+		return err
+	}
+
+	if args.output != OUTPUT_TEXT {
+		return writeDocument(os.Stdout, args.output, outputDocument{
+			Command: commandName(args),
+			Version: newVersionDocument(current, args.format),
+			Matches: newMatchDocuments(project.Matches(), args.format),
+		})
+	}
 
 	if args.verbose {
-		printCurrentVersions(matches, args.format)
-		return
+		printCurrentVersions(project.matches, args.format)
+		return nil
+	}
+	fmt.Println(current.format(args.format))
+	return nil
+}
+
+// newComponentDocuments reads the version of every component of the project,
+// in the given format or else the component's own.
+func newComponentDocuments(project *Project, format string) []componentDocument {
+	documents := []componentDocument{}
+	for _, name := range project.Components() {
+		doc := componentDocument{Name: name}
+		component, err := project.Component(name)
+		var current *Version
+		if err == nil {
+			current, err = component.Current()
+		}
+		if err != nil {
+			doc.Error = newErrorDocument("", err, format).Error
+			documents = append(documents, doc)
+			continue
+		}
+		componentFormat := format
+		if componentFormat == "" {
+			componentFormat = component.Format()
+		}
+		doc.Version = newVersionDocument(current, componentFormat)
+		doc.Consistent = true
+		documents = append(documents, doc)
+	}
+	return documents
+}
+
+func displayComponents(args ExecutionArgs, project *Project) error {
+	documents := newComponentDocuments(project, args.format)
+
+	if args.output != OUTPUT_TEXT {
+		return writeDocument(os.Stdout, args.output, outputDocument{
+			Command:    commandName(args),
+			Components: documents,
+		})
+	}
+
+	if len(documents) == 0 {
+		fmt.Println(aurora.BrightMagenta("The project has no components."))
+		return nil
+	}
+
+	names := project.Components()
+	for _, doc := range documents {
+		if doc.Error != nil && doc.Error.Type == "inconsistent_versions" {
+			fmt.Printf("%-0*s  %s\n", maxLength(names), aurora.Yellow(doc.Name), aurora.BrightMagenta("inconsistent versions"))
+		} else if doc.Error != nil {
+			fmt.Printf("%-0*s  %s\n", maxLength(names), aurora.Yellow(doc.Name), aurora.Red(doc.Error.Message))
+		} else {
+			fmt.Printf("%-0*s  %s\n", maxLength(names), aurora.Yellow(doc.Name), aurora.BrightWhite(doc.Version.Version).Bold())
+		}
+	}
+	return nil
+}
+
+// explainAutoBump lists the commits that call for a new version, and the
+// bump they add up to.
+func explainAutoBump(auto *AutoBump) {
+	since := "the first commit"
+	if auto.Tag != "" {
+		since = auto.Tag
+	}
+	fmt.Println(aurora.BrightMagenta(fmt.Sprintf("Bumping %s for the %d commits since %s:", auto.Part, len(auto.Commits), since)))
+	for _, commit := range auto.Commits {
+		if commit.Part == "" {
+			continue
+		}
+		part := commit.Part
+		if commit.Breaking {
+			part = "breaking"
+		}
+		fmt.Printf("  %-8s %s %s\n", aurora.Yellow(part), aurora.Blue(commit.Hash[:7]), commit.Subject)
+	}
+	if auto.ZeroMajor {
+		fmt.Println(aurora.BrightMagenta("Breaking changes bump the minor version while the major version is 0."))
 	}
-	fmt.Println((*matches)[0].version.format(args.format))
 }
 
-func displayFutureVersion(args ExecutionArgs, matches *[]*VersionMatch) {
-	displayInconsistentVersionMatch(args, matches)
+func planNextVersion(args ExecutionArgs, project *Project) (*Plan, error) {
+	return project.Plan(Bump{Part: args.part, PreRelease: args.preRelease, Format: args.format})
+}
 
-	newVers := (*matches)[0].version.bump(args.part, args.preRelease)
-	version := newVers.format(args.format)
-	fmt.Println(version)
+func displayFutureVersion(args ExecutionArgs, project *Project) error {
+	plan, err := planNextVersion(args, project)
+	if err != nil {
+		return err
+	}
+
+	if args.output != OUTPUT_TEXT {
+		return writeDocument(os.Stdout, args.output, newPlanDocument(commandName(args), project, plan, false))
+	}
+
+	fmt.Println(plan.Next.format(args.format))
+	return nil
 }
 
-func displayNextVersion(args ExecutionArgs, matches *[]*VersionMatch) {
-	displayInconsistentVersionMatch(args, matches)
+func displayNextVersion(args ExecutionArgs, project *Project) error {
+	plan, err := planNextVersion(args, project)
+	if err != nil {
+		return err
+	}
+	if args.commit {
+		err = project.CheckCommit(plan, args.tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.output != OUTPUT_TEXT {
+		return writeDocument(os.Stdout, args.output, newPlanDocument(commandName(args), project, plan, false))
+	}
 
-	printVersionChanges(matches, args.part, args.preRelease, args.format, false)
+	printVersionChanges(plan, false)
+	printChangelogRelease(plan, false)
+	return nil
 }
 
-func applyNextVersion(args ExecutionArgs, matches *[]*VersionMatch) {
-	displayInconsistentVersionMatch(args, matches)
+func applyNextVersion(args ExecutionArgs, project *Project) error {
+	plan, err := planNextVersion(args, project)
+	if err != nil {
+		return err
+	}
 
-	var newVers Version
+	// refuse before any file is touched
+	err = project.Preflight(args.allowDirty)
+	if err != nil {
+		return err
+	}
+	if args.commit {
+		err = project.CheckCommit(plan, args.tag)
+		if err != nil {
+			return err
+		}
+	}
 
-	for _, match := range *matches {
-		newVers = match.version.bump(args.part, args.preRelease)
-		version := newVers.format(args.format)
-		writeVersionUpdate(match.file, match.line, version)
+	matches := project.Matches()
+	err = project.Apply(plan)
+	if err != nil {
+		return err
+	}
+
+	tag := ""
+	if args.commit {
+		err = project.Commit(plan)
+		if err != nil {
+			return fmt.Errorf("the versioned files are updated but not committed: %w", err)
+		}
+	}
+	if args.tag {
+		tag, err = project.Tag(plan)
+		if err != nil {
+			return fmt.Errorf("the update is committed but not tagged: %w", err)
+		}
+	}
+
+	if args.output != OUTPUT_TEXT {
+		doc := newPlanDocument(commandName(args), project, plan, true)
+		// the matches as they were before the update
+		doc.Matches = newMatchDocuments(matches, args.format)
+		doc.Committed = args.commit
+		doc.Tag = tag
+		return writeDocument(os.Stdout, args.output, doc)
 	}
 
 	if args.verbose {
-		printVersionChanges(matches, args.part, args.preRelease, args.format, true)
+		printVersionChanges(plan, true)
+		printChangelogRelease(plan, true)
+		if args.commit {
+			fmt.Println(aurora.BrightGreen("Committed the updated files."))
+		}
+		if tag != "" {
+			fmt.Println(aurora.BrightGreen(fmt.Sprintf("Tagged the commit %s.", tag)))
+		}
 	} else {
-		fmt.Println(newVers.format(args.format))
+		fmt.Println(plan.Next.format(args.format))
+	}
+	return nil
+}
+
+func syncVersions(args ExecutionArgs, project *Project) error {
+	plan, err := project.SyncPlan(Sync{To: args.syncTo, Strategy: args.syncStrategy, Format: args.format})
+	if err != nil {
+		return err
+	}
+
+	matches := project.Matches()
+	applied := args.increment && len(plan.Changes) > 0
+	if applied {
+		err = project.Preflight(args.allowDirty)
+		if err != nil {
+			return err
+		}
+		err = project.Apply(plan)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.output != OUTPUT_TEXT {
+		doc := newPlanDocument(commandName(args), project, plan, applied)
+		doc.Matches = newMatchDocuments(matches, args.format)
+		return writeDocument(os.Stdout, args.output, doc)
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Println(aurora.BrightGreen(fmt.Sprintf("All versioned files are at %s.", plan.Next.format(args.format))))
+		return nil
+	}
+	printVersionChanges(plan, applied)
+	return nil
+}
+
+// displayNotes prints the changelog section of the version as it is
+// written, so it can be piped into a release.
+func displayNotes(args ExecutionArgs, project *Project) error {
+	notes, err := project.Notes(args.notesVersion)
+	if err != nil {
+		return err
+	}
+
+	if args.output != OUTPUT_TEXT {
+		doc := outputDocument{
+			Command: commandName(args),
+			Notes: &notesDocument{
+				File:    notes.File,
+				Heading: notes.Heading,
+				Body:    notes.Body,
+			},
+		}
+		if notes.Version != nil {
+			doc.Version = newVersionDocument(notes.Version, args.format)
+		}
+		return writeDocument(os.Stdout, args.output, doc)
+	}
+
+	if notes.Body != "" {
+		fmt.Println(notes.Body)
 	}
+	return nil
 }
 
-func initialize() {
-	if fileExists(DOVER_CONFIG_FILE) {
+func initialize(dir string) error {
+	configFile := filepath.Join(dir, DOVER_CONFIG_FILE)
+	if fileExists(configFile) {
 		fmt.Println(aurora.BrightMagenta("Dover configuration file `.dover` already exists!"))
-		return
+		return nil
 	}
 
-	err := os.WriteFile(DOVER_CONFIG_FILE, []byte(DOVER_DEFAULT_CONFIG), 0666)
-	ExitOnError(err)
+	err := os.WriteFile(configFile, []byte(DOVER_DEFAULT_CONFIG), 0666)
+	if err != nil {
+		return err
+	}
 	fmt.Println(aurora.BrightGreen("Default `.dover` configuration file created."))
 	fmt.Println("*** Be sure to add your project's versioned files! ***")
+	return nil
 }
@@ -5,37 +5,86 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
 )
 
-func findConfigFile(fileName string) (string, error) {
+// errNoDoverConfig is returned by a configParser when the file exists but
+// has no dover section, in which case the next config file is tried.
+var errNoDoverConfig = errors.New("no dover config entries")
+
+// errConfigNotFound is returned when no dover configuration can be found.
+var errConfigNotFound = errors.New("unable to find dover configuration")
+
+func findConfigFile(dir string, fileName string) (string, error) {
 	/*
 		We're looking for dover config info in the following locations:
 
-			.dover         (universal)
-			pyproject.toml (python)
-			package.json   (javascript)
+			.dover           (universal)
+			dover.yaml       (universal)
+			pyproject.toml   (python)
+			Cargo.toml       (rust)
+			package.json     (javascript)
+			setup.cfg        (python)
+			.bumpversion.cfg (bump2version)
 
 	*/
 
-	root := "./"
-	fileSystem := os.DirFS(root)
-	entries, err := fs.Glob(fileSystem, fileName)
-	check(err)
-
-	if len(entries) == 1 {
-		return entries[0], nil
+	configFile := filepath.Join(dir, fileName)
+	if fileExists(configFile) {
+		return configFile, nil
 	}
 
 	return "", fmt.Errorf("could not find %s config", fileName)
 }
 
+// versionedFile is an entry of versioned_files: a path, with an optional
+// :line notation, and optionally the search regex that finds the version
+// and the template that replaces it, both with a {version} placeholder.
+// occurrence picks the nth version found in the file, 0 keeps them all.
+//
+//	versioned_files = [
+//		"main.go",
+//		{path = "README.md", search = "pip install dover=={version}"},
+//		{path = "Dockerfile", search = "APP_TAG={version}", replace = "APP_TAG={version}"},
+//		{path = "CHANGELOG.md", occurrence = 1},
+//	]
+type versionedFile struct {
+	path       string
+	search     string
+	replace    string
+	occurrence int
+}
+
 type ConfigValues struct {
-	files  []string
-	format string
+	dir           string
+	files         []versionedFile
+	exclude       []string
+	format        string
+	schemeName    string
+	calverFormat  string
+	releases      []releaseLabel
+	scheme        versionScheme
+	source        string
+	configFile    string
+	shadowed      []string
+	name          string
+	components    []ConfigValues
+	sourceOfTruth string
+	commitMessage string
+	tagName       string
+	tagMessage    string
+	requireClean  string
+	branches      []string
+
+	changelog        string
+	changelogHeading string
+	changelogFrom    string
 }
 
 type configParser func(string) (ConfigValues, error)
@@ -45,46 +94,271 @@ func getTomlConfigValues(configFile string) (ConfigValues, error) {
 		Read the .dover configuration file
 	*/
 	cfg, err := toml.LoadFile(configFile)
-	check(err)
+	if err != nil {
+		return ConfigValues{}, err
+	}
 
 	cfgV := ConfigValues{}
 
-	getVersionedFiles := func(c *toml.Tree, pth string) []string {
-		if c.Has(pth) {
-			return c.GetArray(pth).([]string)
+	getString := func(c *toml.Tree, pth string) (string, error) {
+		switch value := c.Get(pth).(type) {
+		case nil:
+			return "", nil
+		case string:
+			return value, nil
 		}
-		return []string{}
+		return "", fmt.Errorf("%s must be a string", pth)
 	}
 
-	getVersionFormat := func(c *toml.Tree, pth string) string {
-		if c.Has(pth) {
-			return c.Get(pth).(string)
+	getInt := func(c *toml.Tree, pth string) (int, error) {
+		switch value := c.Get(pth).(type) {
+		case nil:
+			return 0, nil
+		case int64:
+			return int(value), nil
+		}
+		return 0, fmt.Errorf("%s must be an integer", pth)
+	}
+
+	getVersionedFiles := func(c *toml.Tree, pth string) ([]versionedFile, error) {
+		entries := []interface{}{}
+		switch value := c.Get(pth).(type) {
+		case nil:
+		case []interface{}:
+			entries = value
+		case []*toml.Tree:
+			for _, entry := range value {
+				entries = append(entries, entry)
+			}
+		default:
+			return nil, errors.New("versioned_files must be a list of paths or tables")
+		}
+
+		files := []versionedFile{}
+		for _, entry := range entries {
+			switch entry := entry.(type) {
+			case string:
+				files = append(files, versionedFile{path: entry})
+			case *toml.Tree:
+				var file versionedFile
+				var err error
+				for _, setting := range []struct {
+					value *string
+					key   string
+				}{{&file.path, "path"}, {&file.search, "search"}, {&file.replace, "replace"}} {
+					if *setting.value, err = getString(entry, setting.key); err != nil {
+						return nil, fmt.Errorf("%s: %s", pth, err)
+					}
+				}
+				if file.occurrence, err = getInt(entry, "occurrence"); err != nil {
+					return nil, fmt.Errorf("%s: %s", pth, err)
+				}
+				files = append(files, file)
+			default:
+				return nil, fmt.Errorf("invalid versioned_files entry: %v", entry)
+			}
+		}
+		return files, nil
+	}
+
+	getStrings := func(c *toml.Tree, pth string) ([]string, error) {
+		values := []string{}
+		if !c.Has(pth) {
+			return values, nil
+		}
+		entries, ok := c.Get(pth).([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s must be a list of strings", pth)
+		}
+		for _, entry := range entries {
+			value, ok := entry.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", pth)
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	getReleases := func(c *toml.Tree, pth string) ([]releaseLabel, error) {
+		entries := []interface{}{}
+		switch value := c.Get(pth).(type) {
+		case nil:
+		case []interface{}:
+			entries = value
+		case []*toml.Tree:
+			for _, entry := range value {
+				entries = append(entries, entry)
+			}
+		default:
+			return nil, errors.New("pre_releases must be a list of names or tables")
+		}
+
+		labels := []releaseLabel{}
+		for _, entry := range entries {
+			switch entry := entry.(type) {
+			case string:
+				// "preview"
+				labels = append(labels, releaseLabel{long: entry})
+			case *toml.Tree:
+				// {name = "nightly", short = "n"}
+				name, err := getString(entry, "name")
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s", pth, err)
+				}
+				short, err := getString(entry, "short")
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s", pth, err)
+				}
+				labels = append(labels, releaseLabel{long: name, short: short})
+			default:
+				return nil, fmt.Errorf("invalid pre_releases entry: %v", entry)
+			}
 		}
-		return ""
+		return labels, nil
 	}
 
+	var prefix string
 	if cfg.Has("dover") {
 		// .dover
-		cfgV.files = getVersionedFiles(cfg, "dover.versioned_files")
-		cfgV.format = getVersionFormat(cfg, "dover.version_format")
-		return cfgV, nil
+		prefix = "dover"
 	} else if cfg.Has("tool.dover") {
 		// pyproject.toml
-		cfgV.files = getVersionedFiles(cfg, "tool.dover.versioned_files")
-		cfgV.format = getVersionFormat(cfg, "tool.dover.version_format")
-		return cfgV, nil
+		prefix = "tool.dover"
+	} else if cfg.Has("package.metadata.dover") {
+		// Cargo.toml
+		prefix = "package.metadata.dover"
+	} else if cfg.Has("workspace.metadata.dover") {
+		// Cargo.toml of a workspace
+		prefix = "workspace.metadata.dover"
+	} else {
+		return cfgV, errNoDoverConfig
 	}
 
-	return cfgV, errors.New(fmt.Sprint("No dover config entries in ", configFile))
+	getSection := func(c *toml.Tree, prefix string) (ConfigValues, error) {
+		var err error
+		section := ConfigValues{}
+		section.files, err = getVersionedFiles(c, prefix+".versioned_files")
+		if err != nil {
+			return section, err
+		}
+		section.exclude, err = getStrings(c, prefix+".exclude")
+		if err != nil {
+			return section, err
+		}
+		for _, setting := range []struct {
+			value *string
+			key   string
+		}{
+			{&section.format, "version_format"},
+			{&section.schemeName, "version_scheme"},
+			{&section.calverFormat, "calver_format"},
+			{&section.sourceOfTruth, "source_of_truth"},
+			{&section.commitMessage, "commit_message"},
+			{&section.tagName, "tag_name"},
+			{&section.tagMessage, "tag_message"},
+			{&section.requireClean, "require_clean"},
+			{&section.changelog, "changelog"},
+			{&section.changelogHeading, "changelog_heading"},
+			{&section.changelogFrom, "changelog_from"},
+		} {
+			*setting.value, err = getString(c, prefix+"."+setting.key)
+			if err != nil {
+				return section, err
+			}
+		}
+		section.branches, err = getStrings(c, prefix+".allowed_branches")
+		if err != nil {
+			return section, err
+		}
+		section.releases, err = getReleases(c, prefix+".pre_releases")
+		return section, err
+	}
+
+	cfgV, err = getSection(cfg, prefix)
+	if err != nil {
+		return cfgV, err
+	}
+
+	// [dover.components.api]
+	if components, ok := cfg.Get(prefix + ".components").(*toml.Tree); ok {
+		names := components.Keys()
+		sort.Strings(names)
+		for _, name := range names {
+			component, err := getSection(components, name)
+			if err != nil {
+				return cfgV, fmt.Errorf("component %s: %s", name, err)
+			}
+			component.name = name
+			cfgV.components = append(cfgV.components, component)
+		}
+	}
+	return cfgV, nil
 }
 
-func readJSONConfig(configFile string) []byte {
+func readJSONConfig(configFile string) ([]byte, error) {
 	file, err := os.Open(configFile)
-	check(err)
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
 
-	content, _ := io.ReadAll(file)
-	return content
+	return io.ReadAll(file)
+}
+
+// doverSection is the dover configuration in the json and yaml config files.
+type doverSection struct {
+	VersionFormat  string          `json:"version_format" yaml:"version_format"`
+	VersionScheme  string          `json:"version_scheme" yaml:"version_scheme"`
+	CalverFormat   string          `json:"calver_format" yaml:"calver_format"`
+	PreReleases    []releaseLabel  `json:"pre_releases" yaml:"pre_releases"`
+	VersionedFiles []versionedFile `json:"versioned_files" yaml:"versioned_files"`
+	Exclude        []string        `json:"exclude" yaml:"exclude"`
+	SourceOfTruth  string          `json:"source_of_truth" yaml:"source_of_truth"`
+	CommitMessage  string          `json:"commit_message" yaml:"commit_message"`
+	TagName        string          `json:"tag_name" yaml:"tag_name"`
+	TagMessage     string          `json:"tag_message" yaml:"tag_message"`
+	RequireClean   string          `json:"require_clean" yaml:"require_clean"`
+	Branches       []string        `json:"allowed_branches" yaml:"allowed_branches"`
+
+	Changelog        string `json:"changelog" yaml:"changelog"`
+	ChangelogHeading string `json:"changelog_heading" yaml:"changelog_heading"`
+	ChangelogFrom    string `json:"changelog_from" yaml:"changelog_from"`
+
+	Components map[string]*doverSection `json:"components" yaml:"components"`
+}
+
+func (d *doverSection) configValues() ConfigValues {
+	cfgV := ConfigValues{
+		format:        d.VersionFormat,
+		schemeName:    d.VersionScheme,
+		calverFormat:  d.CalverFormat,
+		releases:      d.PreReleases,
+		files:         d.VersionedFiles,
+		exclude:       d.Exclude,
+		sourceOfTruth: d.SourceOfTruth,
+		commitMessage: d.CommitMessage,
+		tagName:       d.TagName,
+		tagMessage:    d.TagMessage,
+		requireClean:  d.RequireClean,
+		branches:      d.Branches,
+
+		changelog:        d.Changelog,
+		changelogHeading: d.ChangelogHeading,
+		changelogFrom:    d.ChangelogFrom,
+	}
+
+	names := []string{}
+	for name := range d.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		component := d.Components[name].configValues()
+		component.name = name
+		cfgV.components = append(cfgV.components, component)
+	}
+	return cfgV
 }
 
 func parseJSONConfig(content string) (ConfigValues, error) {
@@ -92,10 +366,7 @@ func parseJSONConfig(content string) (ConfigValues, error) {
 		Read the project.json configuration file
 	*/
 	type ProjectJSON struct {
-		Dover struct {
-			VersionFormat  string   `json:"version_format"`
-			VersionedFiles []string `json:"versioned_files"`
-		} `json:"dover"`
+		Dover *doverSection `json:"dover"`
 	}
 
 	cfgV := ConfigValues{}
@@ -106,18 +377,136 @@ func parseJSONConfig(content string) (ConfigValues, error) {
 		return cfgV, fmt.Errorf("json parsing failed: %s", err)
 	}
 
-	if len(payload.Dover.VersionedFiles) == 0 {
+	if payload.Dover == nil {
+		return cfgV, errNoDoverConfig
+	}
+
+	if len(payload.Dover.VersionedFiles) == 0 && len(payload.Dover.Components) == 0 {
 		return cfgV, fmt.Errorf("no `dover` section or `dover.versioned_files` contains no file references")
 	}
 
-	cfgV.format = payload.Dover.VersionFormat
-	cfgV.files = payload.Dover.VersionedFiles
+	return payload.Dover.configValues(), nil
+}
 
-	return cfgV, nil
+func getYAMLConfigValues(configFile string) (ConfigValues, error) {
+	/*
+		Read the dover.yaml configuration file
+	*/
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return ConfigValues{}, err
+	}
+
+	var payload struct {
+		Dover *doverSection `yaml:"dover"`
+	}
+	err = yaml.Unmarshal(content, &payload)
+	if err != nil {
+		return ConfigValues{}, fmt.Errorf("yaml parsing failed: %s", err)
+	}
+
+	if payload.Dover == nil {
+		return ConfigValues{}, errNoDoverConfig
+	}
+
+	return payload.Dover.configValues(), nil
+}
+
+// UnmarshalJSON reads a pre_releases entry, which is either a plain name or
+// an object with a name and a short spelling.
+func (l *releaseLabel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		l.long = name
+		return nil
+	}
+
+	var label struct {
+		Name  string `json:"name"`
+		Short string `json:"short"`
+	}
+	if err := json.Unmarshal(data, &label); err != nil {
+		return errors.New("pre_releases must be a list of names or objects")
+	}
+	l.long = label.Name
+	l.short = label.Short
+	return nil
+}
+
+// UnmarshalJSON reads a versioned_files entry, which is either a path or an
+// object with a path, search, replace and occurrence.
+func (f *versionedFile) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		f.path = path
+		return nil
+	}
+
+	var file struct {
+		Path       string `json:"path"`
+		Search     string `json:"search"`
+		Replace    string `json:"replace"`
+		Occurrence int    `json:"occurrence"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return errors.New("versioned_files must be a list of paths or objects")
+	}
+	f.path = file.Path
+	f.search = file.Search
+	f.replace = file.Replace
+	f.occurrence = file.Occurrence
+	return nil
+}
+
+// UnmarshalYAML reads a pre_releases entry, which is either a plain name or
+// a mapping with a name and a short spelling.
+func (l *releaseLabel) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		l.long = value.Value
+		return nil
+	}
+
+	var label struct {
+		Name  string `yaml:"name"`
+		Short string `yaml:"short"`
+	}
+	if err := value.Decode(&label); err != nil {
+		return errors.New("pre_releases must be a list of names or mappings")
+	}
+	l.long = label.Name
+	l.short = label.Short
+	return nil
+}
+
+// UnmarshalYAML reads a versioned_files entry, which is either a path or a
+// mapping with a path, search, replace and occurrence.
+func (f *versionedFile) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		f.path = value.Value
+		return nil
+	}
+
+	var file struct {
+		Path       string `yaml:"path"`
+		Search     string `yaml:"search"`
+		Replace    string `yaml:"replace"`
+		Occurrence int    `yaml:"occurrence"`
+	}
+	if err := value.Decode(&file); err != nil {
+		return errors.New("versioned_files must be a list of paths or mappings")
+	}
+	f.path = file.Path
+	f.search = file.Search
+	f.replace = file.Replace
+	f.occurrence = file.Occurrence
+	return nil
 }
 
 func getJSONConfigValues(configFile string) (ConfigValues, error) {
-	content := readJSONConfig(configFile)
+	content, err := readJSONConfig(configFile)
+	if err != nil {
+		return ConfigValues{}, err
+	}
 	cfgV, err := parseJSONConfig(string(content))
 	if err != nil {
 		return cfgV, err
@@ -128,58 +517,327 @@ func getJSONConfigValues(configFile string) (ConfigValues, error) {
 
 const (
 	DOVER_CONFIG_FILE        = ".dover"
+	DOVER_YAML_CONFIG_FILE   = "dover.yaml"
 	PYPROJECT_CONFIG_FILE    = "pyproject.toml"
+	CARGO_CONFIG_FILE        = "Cargo.toml"
 	PACKAGE_JSON_CONFIG_FILE = "package.json"
+	SETUP_CFG_CONFIG_FILE    = "setup.cfg"
+	BUMPVERSION_CONFIG_FILE  = ".bumpversion.cfg"
 )
 
+// CONFIG_FILES are the files dover reads its configuration from, in order of
+// precedence: the first one with a dover section is used.
+var CONFIG_FILES = []string{
+	DOVER_CONFIG_FILE,
+	DOVER_YAML_CONFIG_FILE,
+	PYPROJECT_CONFIG_FILE,
+	CARGO_CONFIG_FILE,
+	PACKAGE_JSON_CONFIG_FILE,
+	SETUP_CFG_CONFIG_FILE,
+	BUMPVERSION_CONFIG_FILE,
+}
+
+// CONFIG_PARSERS has the parser for each config file, by its name or else by
+// its extension.
+var CONFIG_PARSERS = map[string]configParser{
+	DOVER_CONFIG_FILE:       getTomlConfigValues,
+	BUMPVERSION_CONFIG_FILE: getBumpversionConfigValues,
+	".toml":                 getTomlConfigValues,
+	".json":                 getJSONConfigValues,
+	".yaml":                 getYAMLConfigValues,
+	".yml":                  getYAMLConfigValues,
+	".cfg":                  getSetupCfgConfigValues,
+}
+
 const DOVER_DEFAULT_CONFIG = `[dover]
 version_format = "000-A.0"
 versioned_files = [
 ]
 `
 
-func configValues() (ConfigValues, error) {
-	configOrder := []string{DOVER_CONFIG_FILE, PYPROJECT_CONFIG_FILE, PACKAGE_JSON_CONFIG_FILE}
-
-	possibleConfigFiles := map[string]configParser{
-		DOVER_CONFIG_FILE:        getTomlConfigValues,
-		PYPROJECT_CONFIG_FILE:    getTomlConfigValues,
-		PACKAGE_JSON_CONFIG_FILE: getJSONConfigValues,
-	}
-
+// configValues reads the first dover configuration found in dir. Any other
+// config file in dir with a dover section is listed in the shadowed config
+// files, as it is not used.
+func configValues(dir string) (ConfigValues, error) {
 	var cfg ConfigValues
+	found := false
 
-	for _, fileName := range configOrder {
-
-		configParser := possibleConfigFiles[fileName]
-		cfgFile, err := findConfigFile(fileName)
+	for _, fileName := range CONFIG_FILES {
+		cfgFile, err := findConfigFile(dir, fileName)
 		if err != nil {
 			continue
 		}
 
-		cfg, err := configParser(cfgFile)
-		if err != nil {
-			fmt.Printf("%s: %s", fileName, err)
+		if found {
+			if hasDoverConfig(cfgFile) {
+				cfg.shadowed = append(cfg.shadowed, fileName)
+			}
 			continue
 		}
 
-		if len(cfg.files) == 0 {
-			return cfg, fmt.Errorf("`%s` config has no versioned_files", fileName)
+		cfg, err = configFileValues(cfgFile, dir)
+		if errors.Is(err, errNoDoverConfig) {
+			continue
+		}
+		if err != nil {
+			return cfg, err
 		}
+		found = true
+	}
 
-		for _, filePath := range cfg.files {
-			filePath, _ := splitFileAndLineNotation(filePath)
-			if !fileExists(filePath) {
-				return cfg, fmt.Errorf("no such file: %s", filePath)
-			}
+	if !found {
+		return cfg, &ConfigError{Err: errConfigNotFound}
+	}
+	return cfg, nil
+}
+
+func hasDoverConfig(configFile string) bool {
+	configParser, err := newConfigParser(configFile)
+	if err != nil {
+		return false
+	}
+	_, err = configParser(configFile)
+	return !errors.Is(err, errNoDoverConfig)
+}
+
+// discoverConfigValues reads the first dover configuration found in start or
+// the closest of its parents, going no further up than the root of the git
+// repository start is in.
+func discoverConfigValues(start string) (ConfigValues, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return ConfigValues{}, err
+	}
+	for {
+		cfg, err := configValues(dir)
+		if !errors.Is(err, errConfigNotFound) {
+			return cfg, err
+		}
+		parent := filepath.Dir(dir)
+		if isGitRoot(dir) || parent == dir {
+			return cfg, err
 		}
+		dir = parent
+	}
+}
+
+func isGitRoot(dir string) bool {
+	// .git is a file in worktrees and submodules
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
 
-		if cfg.format == "" {
-			cfg.format = "000.A.0"
+// newConfigParser picks the parser for a config file by its name.
+func newConfigParser(configFile string) (configParser, error) {
+	fileName := filepath.Base(configFile)
+	if configParser, found := CONFIG_PARSERS[fileName]; found {
+		return configParser, nil
+	}
+	if configParser, found := CONFIG_PARSERS[strings.ToLower(filepath.Ext(fileName))]; found {
+		return configParser, nil
+	}
+	return nil, fmt.Errorf("unknown config file type: %s", fileName)
+}
+
+// configFileValues reads the dover configuration in configFile, with the
+// versioned files relative to dir.
+func configFileValues(configFile string, dir string) (ConfigValues, error) {
+	fileName := filepath.Base(configFile)
+	configParser, err := newConfigParser(configFile)
+	if err != nil {
+		return ConfigValues{}, &ConfigError{Err: err}
+	}
+
+	cfg, err := configParser(configFile)
+	if errors.Is(err, errNoDoverConfig) {
+		return cfg, err
+	}
+	if err != nil {
+		return cfg, &ConfigError{File: fileName, Err: err}
+	}
+	cfg.dir = dir
+	cfg.source = fileName
+	cfg.configFile = configFile
+
+	// a config that is also a versioned file (.bumpversion.cfg) is named
+	// relative to dir like the others
+	for index, file := range cfg.files {
+		if relPath, err := filepath.Rel(dir, file.path); file.path == configFile && err == nil {
+			cfg.files[index].path = relPath
 		}
+	}
 
+	for index := range cfg.components {
+		component := &cfg.components[index]
+		component.dir = dir
+		component.source = fileName
+		component.configFile = configFile
+		component.inherit(cfg)
+		if err := validateConfigValues(component, fileName); err != nil {
+			return cfg, &ConfigError{File: fileName, Err: fmt.Errorf("component %s: %w", component.name, err)}
+		}
+	}
+	if len(cfg.components) > 0 && len(cfg.files) == 0 {
 		return cfg, nil
 	}
 
-	return cfg, fmt.Errorf("unable to find dover configuration")
+	return cfg, validateConfigValues(&cfg, fileName)
+}
+
+func hasVersionedFile(files []versionedFile, path string) bool {
+	paths := versionedFilePaths(files)
+	return IndexOf(&paths, path) != -1
+}
+
+// versionedFilePaths lists the paths of the versioned files, without their
+// line numbers or key paths.
+func versionedFilePaths(files []versionedFile) []string {
+	paths := []string{}
+	for _, file := range files {
+		filePath, _ := splitFileAndKeyPath(file.path)
+		filePath, _ = splitFileAndLineNotation(filePath)
+		if IndexOf(&paths, filePath) == -1 {
+			paths = append(paths, filePath)
+		}
+	}
+	return paths
+}
+
+// inherit takes the settings a component leaves out from the top level
+// dover configuration.
+func (cfg *ConfigValues) inherit(parent ConfigValues) {
+	if cfg.format == "" {
+		cfg.format = parent.format
+	}
+	if cfg.schemeName == "" {
+		cfg.schemeName = parent.schemeName
+	}
+	if cfg.calverFormat == "" {
+		cfg.calverFormat = parent.calverFormat
+	}
+	if len(cfg.releases) == 0 {
+		cfg.releases = parent.releases
+	}
+	if cfg.commitMessage == "" {
+		cfg.commitMessage = parent.commitMessage
+	}
+	if cfg.tagName == "" {
+		cfg.tagName = parent.tagName
+	}
+	if cfg.tagMessage == "" {
+		cfg.tagMessage = parent.tagMessage
+	}
+	if cfg.requireClean == "" {
+		cfg.requireClean = parent.requireClean
+	}
+	if len(cfg.branches) == 0 {
+		cfg.branches = parent.branches
+	}
+	if cfg.changelogHeading == "" {
+		cfg.changelogHeading = parent.changelogHeading
+	}
+	if cfg.changelogFrom == "" {
+		cfg.changelogFrom = parent.changelogFrom
+	}
+	cfg.exclude = append(cfg.exclude, parent.exclude...)
+}
+
+// validateConfigValues checks the configuration of fileName, expanding its
+// versioned files and setting up its version scheme as it goes.
+func validateConfigValues(cfg *ConfigValues, fileName string) error {
+	var err error
+	dir := cfg.dir
+
+	if len(cfg.files) == 0 {
+		return &ConfigError{Err: fmt.Errorf("`%s` config has no versioned_files", fileName)}
+	}
+
+	for _, file := range cfg.files {
+		if file.path == "" {
+			return &ConfigError{File: fileName, Err: errors.New("versioned_files entry has no path")}
+		}
+	}
+
+	// globs are expanded relative to the project directory
+	cfg.files, err = expandVersionedFiles(dir, cfg.files, cfg.exclude)
+	if err != nil {
+		return &ConfigError{File: fileName, Err: err}
+	}
+
+	for _, file := range cfg.files {
+		filePath, keyPath := splitFileAndKeyPath(file.path)
+		if keyPath == "" {
+			filePath, _, err = parseVersionedFileConfig(filePath)
+			if err != nil {
+				return err
+			}
+		} else if _, err := newKeyPathFinder(filePath); err != nil {
+			return &ConfigError{Err: err}
+		} else if file.search != "" {
+			return &ConfigError{Err: fmt.Errorf("%s: a key path can not be used with a search pattern", file.path)}
+		} else if file.occurrence != 0 {
+			return &ConfigError{Err: fmt.Errorf("%s: a key path can not be used with an occurrence", file.path)}
+		}
+		if file.occurrence < 0 {
+			return &ConfigError{Err: fmt.Errorf("%s: occurrence must be 1 or more", file.path)}
+		}
+		if !fileExists(projectPath(dir, filePath)) {
+			return &ConfigError{Err: fmt.Errorf("no such file: %s", filePath)}
+		}
+		if file.replace != "" && !strings.Contains(file.replace, VERSION_PLACEHOLDER) {
+			return &ConfigError{Err: fmt.Errorf("%s: replace template `%s` has no %s", file.path, file.replace, VERSION_PLACEHOLDER)}
+		}
+		if file.replace != "" && file.search == "" {
+			return &ConfigError{Err: fmt.Errorf("%s: replace needs a search pattern", file.path)}
+		}
+	}
+
+	if cfg.sourceOfTruth != "" && !hasVersionedFile(cfg.files, cfg.sourceOfTruth) {
+		return &ConfigError{Err: fmt.Errorf("source_of_truth %s is not one of the versioned_files", cfg.sourceOfTruth)}
+	}
+
+	if cfg.tagName != "" && !strings.Contains(cfg.tagName, VERSION_PLACEHOLDER) {
+		return &ConfigError{File: fileName, Err: fmt.Errorf("tag_name `%s` has no %s", cfg.tagName, VERSION_PLACEHOLDER)}
+	}
+	if IndexOf(&CLEAN_CHECKS, cfg.requireClean) == -1 {
+		return &ConfigError{File: fileName, Err: fmt.Errorf("unknown require_clean: %s, expected %s or %s", cfg.requireClean, CLEAN_TREE, CLEAN_VERSIONED_FILES)}
+	}
+	for _, branch := range cfg.branches {
+		if err := validateGlob(branch); err != nil {
+			return &ConfigError{File: fileName, Err: fmt.Errorf("allowed_branches: %w", err)}
+		}
+	}
+
+	if cfg.changelog != "" && !fileExists(projectPath(dir, cfg.changelog)) {
+		return &ConfigError{File: fileName, Err: fmt.Errorf("no such changelog: %s", cfg.changelog)}
+	}
+	if IndexOf(&CHANGELOG_SOURCES, cfg.changelogFrom) == -1 {
+		return &ConfigError{File: fileName, Err: fmt.Errorf("unknown changelog_from: %s, expected %s or %s", cfg.changelogFrom, CHANGELOG_FROM_UNRELEASED, CHANGELOG_FROM_COMMITS)}
+	}
+	if cfg.changelogHeading != "" {
+		if level, _ := markdownHeading(cfg.changelogHeading); level == 0 || !strings.Contains(cfg.changelogHeading, VERSION_PLACEHOLDER) {
+			return &ConfigError{File: fileName, Err: fmt.Errorf("changelog_heading `%s` is not a markdown heading with a %s", cfg.changelogHeading, VERSION_PLACEHOLDER)}
+		}
+	}
+
+	if cfg.format == "" {
+		cfg.format = DEFAULT_FORMAT
+	}
+
+	if _, err := NewVersionFormater(cfg.format); err != nil {
+		return err
+	}
+
+	cfg.scheme, err = newVersionScheme(*cfg)
+	if err != nil {
+		return &ConfigError{Err: err}
+	}
+
+	for _, file := range cfg.files {
+		if _, err := newFileFinder(file, cfg.scheme); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
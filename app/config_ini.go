@@ -0,0 +1,162 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+)
+
+func readINIConfig(configFile string) ([]*iniSection, error) {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseINI(string(content))
+}
+
+func getSetupCfgConfigValues(configFile string) (ConfigValues, error) {
+	/*
+		Read the [dover] section of setup.cfg:
+
+			[dover]
+			version_format = 000.r0
+			versioned_files =
+				setup.py
+				mypackage/__init__.py
+	*/
+	sections, err := readINIConfig(configFile)
+	if err != nil {
+		return ConfigValues{}, err
+	}
+
+	for _, section := range sections {
+		if section.name != "dover" {
+			continue
+		}
+		cfgV := ConfigValues{
+			format:        section.get("version_format"),
+			schemeName:    section.get("version_scheme"),
+			calverFormat:  section.get("calver_format"),
+			exclude:       section.getList("exclude"),
+			sourceOfTruth: section.get("source_of_truth"),
+			commitMessage: section.get("commit_message"),
+			tagName:       section.get("tag_name"),
+			tagMessage:    section.get("tag_message"),
+			requireClean:  section.get("require_clean"),
+			branches:      section.getList("allowed_branches"),
+
+			changelog:        section.get("changelog"),
+			changelogHeading: section.get("changelog_heading"),
+			changelogFrom:    section.get("changelog_from"),
+		}
+		for _, path := range section.getList("versioned_files") {
+			cfgV.files = append(cfgV.files, versionedFile{path: path})
+		}
+		for _, name := range section.getList("pre_releases") {
+			cfgV.releases = append(cfgV.releases, releaseLabel{long: name})
+		}
+		return cfgV, nil
+	}
+	return ConfigValues{}, errNoDoverConfig
+}
+
+const (
+	BUMPVERSION_SECTION         = "bumpversion"
+	BUMPVERSION_FILE_PREFIX     = "bumpversion:file:"
+	BUMPVERSION_GLOB_PREFIX     = "bumpversion:glob:"
+	BUMPVERSION_CURRENT_VERSION = "{current_version}"
+	BUMPVERSION_NEW_VERSION     = "{new_version}"
+)
+
+func getBumpversionConfigValues(configFile string) (ConfigValues, error) {
+	/*
+		Read the files of a bump2version .bumpversion.cfg:
+
+			[bumpversion]
+			current_version = 0.3.0
+
+			[bumpversion:file:setup.py]
+			search = version="{current_version}"
+			replace = version="{new_version}"
+	*/
+	sections, err := readINIConfig(configFile)
+	if err != nil {
+		return ConfigValues{}, err
+	}
+
+	cfgV := ConfigValues{}
+	found := false
+	current := ""
+	fileSections := []*iniSection{}
+	paths := []string{}
+	for _, section := range sections {
+		var path string
+		switch {
+		case section.name == BUMPVERSION_SECTION:
+			found = true
+			current = section.get("current_version")
+			cfgV.commitMessage = bumpversionTemplate(section.get("message"))
+			cfgV.tagName = bumpversionTemplate(section.get("tag_name"))
+			cfgV.tagMessage = bumpversionTemplate(section.get("tag_message"))
+			// bump2version refuses a dirty tree unless allow_dirty is true
+			if value := strings.ToLower(section.get("allow_dirty")); value == "false" || value == "no" || value == "0" {
+				cfgV.requireClean = CLEAN_TREE
+			}
+			continue
+		case strings.HasPrefix(section.name, BUMPVERSION_FILE_PREFIX):
+			path = strings.TrimPrefix(section.name, BUMPVERSION_FILE_PREFIX)
+		case strings.HasPrefix(section.name, BUMPVERSION_GLOB_PREFIX):
+			path = strings.TrimPrefix(section.name, BUMPVERSION_GLOB_PREFIX)
+		default:
+			continue
+		}
+		fileSections = append(fileSections, section)
+		paths = append(paths, path)
+	}
+
+	if !found {
+		return cfgV, errNoDoverConfig
+	}
+	if current == "" {
+		return cfgV, errors.New("[bumpversion] has no current_version")
+	}
+	if len(fileSections) == 0 {
+		return cfgV, errors.New("no [bumpversion:file:...] sections")
+	}
+
+	for index, section := range fileSections {
+		// bump2version replaces every occurrence of the current version, and
+		// only of the current version
+		search := section.get("search")
+		if search == "" {
+			search = BUMPVERSION_CURRENT_VERSION
+		}
+		replace := bumpversionTemplate(section.get("replace"))
+		if replace == VERSION_PLACEHOLDER {
+			replace = ""
+		}
+		cfgV.files = append(cfgV.files, versionedFile{path: paths[index], search: bumpversionSearch(search, current), replace: replace})
+	}
+	// the current_version of the config is bumped along with the files
+	currentVersionSearch := `^current_version\s*[=:]\s*` + VERSION_GROUP + regexp.QuoteMeta(current) + `)\s*$`
+	cfgV.files = append(cfgV.files, versionedFile{path: configFile, search: currentVersionSearch})
+	return cfgV, nil
+}
+
+// bumpversionTemplate turns the {new_version} of a bump2version template
+// into dover's {version}.
+func bumpversionTemplate(template string) string {
+	return strings.ReplaceAll(template, BUMPVERSION_NEW_VERSION, VERSION_PLACEHOLDER)
+}
+
+// bumpversionSearch turns a bump2version search, which is plain text, into
+// a search regex that finds the current version as it is written, and no
+// other version.
+func bumpversionSearch(search string, current string) string {
+	parts := strings.Split(search, BUMPVERSION_CURRENT_VERSION)
+	for index, part := range parts {
+		parts[index] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, VERSION_GROUP+regexp.QuoteMeta(current)+")")
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -28,6 +29,49 @@ func TestJSONConfigWithValues(t *testing.T) {
 	assert.Equal(t, 1, len(cfg.files))
 }
 
+func TestJSONConfigWithVersionedFileObjects(t *testing.T) {
+	projectFile := `{
+	"name": "Some Project",
+	"version": "0.0.0",
+	"dover": {
+		"versioned_files": [
+			"package.json",
+			{"path": "README.md", "search": "npm install project@{version}"},
+			{"path": "CHANGELOG.md", "occurrence": 1}
+		]
+	}
+}`
+	cfg, err := parseJSONConfig(projectFile)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []versionedFile{
+		{path: "package.json"},
+		{path: "README.md", search: "npm install project@{version}"},
+		{path: "CHANGELOG.md", occurrence: 1},
+	}, cfg.files)
+}
+
+func TestJSONConfigWithComponents(t *testing.T) {
+	projectFile := `{
+	"name": "Some Project",
+	"dover": {
+		"version_format": "000a0",
+		"components": {
+			"web": {"versioned_files": ["web/package.json#version"]},
+			"api": {"version_scheme": "pep440", "versioned_files": ["api/pyproject.toml#project.version"]}
+		}
+	}
+}`
+	cfg, err := parseJSONConfig(projectFile)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(cfg.files))
+	assert.Equal(t, 2, len(cfg.components))
+	assert.Equal(t, "api", cfg.components[0].name)
+	assert.Equal(t, "pep440", cfg.components[0].schemeName)
+	assert.Equal(t, []versionedFile{{path: "web/package.json#version"}}, cfg.components[1].files)
+}
+
 func TestJSONConfigWithValues2(t *testing.T) {
 	projectFile := `{
 	"name": "Some Project",
@@ -95,6 +139,21 @@ func TestJSONConfigWithNoFileVar(t *testing.T) {
 	assert.Equal(t, 0, len(cfg.files))
 }
 
+func TestJSONConfigWithPreReleases(t *testing.T) {
+	projectFile := `{
+	"name": "Some Project",
+	"version": "0.0.0",
+	"dover": {
+		"versioned_files": ["project.json"],
+		"pre_releases": [{"name": "nightly", "short": "n"}, "preview", "rc"]
+	}
+}`
+	cfg, err := parseJSONConfig(projectFile)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []releaseLabel{{long: "nightly", short: "n"}, {long: "preview"}, {long: "rc"}}, cfg.releases)
+}
+
 type ConfigTestSuite struct {
 	suite.Suite
 	homeDir string
@@ -103,8 +162,10 @@ type ConfigTestSuite struct {
 
 func (suite *ConfigTestSuite) writeFile(name, content string) {
 	file := filepath.Join(suite.tempDir, name)
-	err := os.WriteFile(file, []byte(content), 0666)
-	ExitOnError(err)
+	err := os.MkdirAll(filepath.Dir(file), 0777)
+	suite.Require().Nil(err)
+	err = os.WriteFile(file, []byte(content), 0666)
+	suite.Require().Nil(err)
 }
 
 func (suite *ConfigTestSuite) SetupTest() {
@@ -114,18 +175,18 @@ func (suite *ConfigTestSuite) SetupTest() {
 	suite.writeFile("coding.go", `\nVERSION = "0.1.0-a0"\n`)
 	suite.writeFile("overhill.go", `\n__version__ = "0.1.0-a0"\n`)
 	err := os.Chdir(suite.tempDir)
-	ExitOnError(err)
+	suite.Require().Nil(err)
 }
 
 func (suite *ConfigTestSuite) TeardownTest() {
 	err := os.Chdir(suite.homeDir)
-	ExitOnError(err)
+	suite.Require().Nil(err)
 	err = os.RemoveAll(suite.tempDir)
-	ExitOnError(err)
+	suite.Require().Nil(err)
 }
 
 func (suite *ConfigTestSuite) TestNoConfigFiles() {
-	_, err := configValues()
+	_, err := configValues(".")
 	suite.NotNil(err)
 	suite.Equal("unable to find dover configuration", fmt.Sprint(err))
 }
@@ -133,7 +194,7 @@ func (suite *ConfigTestSuite) TestNoConfigFiles() {
 func (suite *ConfigTestSuite) TestInvalidDoverConfigFile() {
 	suite.writeFile(".dover", `[dover]`)
 
-	_, err := configValues()
+	_, err := configValues(".")
 	suite.NotNil(err)
 	suite.Equal("`.dover` config has no versioned_files", fmt.Sprint(err))
 }
@@ -146,11 +207,28 @@ versioned_files = [
 ]
 `)
 
-	_, err := configValues()
+	_, err := configValues(".")
 	suite.NotNil(err)
 	suite.Equal("no such file: dunnowherethisis.go", fmt.Sprint(err))
 }
 
+func (suite *ConfigTestSuite) TestConfigValueOfTheWrongType() {
+	var tests = []struct {
+		setting, expected string
+	}{
+		{`version_format = 3`, ".dover: dover.version_format must be a string"},
+		{`require_clean = true`, ".dover: dover.require_clean must be a string"},
+		{`pre_releases = [{name = 1}]`, ".dover: dover.pre_releases: name must be a string"},
+	}
+
+	for _, tt := range tests {
+		suite.writeFile(".dover", "[dover]\n"+tt.setting+"\nversioned_files = [\"coding.go\"]\n")
+		_, err := configValues(".")
+		suite.IsType(&ConfigError{}, err)
+		suite.EqualError(err, tt.expected)
+	}
+}
+
 func (suite *ConfigTestSuite) TestValidDoverConfigFile() {
 	suite.writeFile(".dover", `[dover]
 versioned_files = [
@@ -159,7 +237,7 @@ versioned_files = [
 ]
 `)
 
-	cfg, err := configValues()
+	cfg, err := configValues(".")
 	suite.Nil(err)
 	suite.Equal("000.A.0", cfg.format)
 	suite.Equal(2, len(cfg.files))
@@ -174,7 +252,7 @@ versioned_files = [
 ]
 `)
 
-	cfg, err := configValues()
+	cfg, err := configValues(".")
 	suite.Nil(err)
 	suite.Equal("000.A.0", cfg.format)
 	suite.Equal(2, len(cfg.files))
@@ -193,12 +271,400 @@ func (suite *ConfigTestSuite) TestValidPackageJsonConfigFile() {
 	}
 }`)
 
-	cfg, err := configValues()
+	cfg, err := configValues(".")
 	suite.Nil(err)
 	suite.Equal("000+a0", cfg.format)
 	suite.Equal(2, len(cfg.files))
 }
 
+func (suite *ConfigTestSuite) TestPep440SchemeConfig() {
+	suite.writeFile("pyproject.toml", `[tool.dover]
+version_scheme = "pep440"
+versioned_files = [
+	"coding.go",
+	"overhill.go"
+]
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal(PEP440_SCHEME, cfg.scheme.name())
+}
+
+func (suite *ConfigTestSuite) TestUnknownSchemeConfig() {
+	suite.writeFile(".dover", `[dover]
+version_scheme = "roman"
+versioned_files = [
+	"coding.go"
+]
+`)
+
+	_, err := configValues(".")
+	suite.NotNil(err)
+	suite.Equal("unknown version_scheme: roman", fmt.Sprint(err))
+}
+
+func (suite *ConfigTestSuite) TestSourceOfTruthConfig() {
+	suite.writeFile(".dover", `[dover]
+versioned_files = [
+	"coding.go:3"
+]
+source_of_truth = "coding.go"
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal("coding.go", cfg.sourceOfTruth)
+
+	suite.writeFile(".dover", `[dover]
+versioned_files = [
+	"coding.go"
+]
+source_of_truth = "setup.py"
+`)
+
+	_, err = configValues(".")
+	suite.Equal("source_of_truth setup.py is not one of the versioned_files", fmt.Sprint(err))
+}
+
+func (suite *ConfigTestSuite) TestGitChecksConfig() {
+	suite.writeFile(".dover", `[dover]
+versioned_files = ["coding.go"]
+require_clean = "versioned_files"
+allowed_branches = ["main", "release/*"]
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal(CLEAN_VERSIONED_FILES, cfg.requireClean)
+	suite.Equal([]string{"main", "release/*"}, cfg.branches)
+
+	suite.writeFile(".dover", `[dover]
+versioned_files = ["coding.go"]
+require_clean = "yes"
+`)
+
+	_, err = configValues(".")
+	suite.Equal(".dover: unknown require_clean: yes, expected tree or versioned_files", fmt.Sprint(err))
+
+	suite.writeFile(".dover", `[dover]
+versioned_files = ["coding.go"]
+tag_name = "release"
+`)
+
+	_, err = configValues(".")
+	suite.Equal(".dover: tag_name `release` has no {version}", fmt.Sprint(err))
+}
+
+func (suite *ConfigTestSuite) TestChangelogConfig() {
+	suite.writeFile("HISTORY.md", "## Unreleased\n")
+	suite.writeFile(".dover", `[dover]
+versioned_files = ["coding.go"]
+changelog = "HISTORY.md"
+changelog_heading = "##### v{version} ({month} {year})"
+changelog_from = "unreleased"
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal("HISTORY.md", cfg.changelog)
+	suite.Equal("##### v{version} ({month} {year})", cfg.changelogHeading)
+
+	tests := []struct {
+		config   string
+		expected string
+	}{
+		{`changelog = "CHANGES.md"`, ".dover: no such changelog: CHANGES.md"},
+		{`changelog_from = "tags"`, ".dover: unknown changelog_from: tags, expected unreleased or commits"},
+		{`changelog_heading = "v{version}"`, ".dover: changelog_heading `v{version}` is not a markdown heading with a {version}"},
+		{`changelog_heading = "## Release"`, ".dover: changelog_heading `## Release` is not a markdown heading with a {version}"},
+	}
+	for _, test := range tests {
+		suite.writeFile(".dover", "[dover]\nversioned_files = [\"coding.go\"]\n"+test.config+"\n")
+		_, err := configValues(".")
+		suite.Equal(test.expected, fmt.Sprint(err))
+	}
+}
+
+func (suite *ConfigTestSuite) TestCalverSchemeConfig() {
+	suite.writeFile(".dover", `[dover]
+version_scheme = "calver"
+calver_format = "YYYY.0M.MICRO"
+versioned_files = [
+	"coding.go"
+]
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal(CALVER_SCHEME, cfg.scheme.name())
+}
+
+func (suite *ConfigTestSuite) TestInvalidCalverFormatConfig() {
+	suite.writeFile(".dover", `[dover]
+version_scheme = "calver"
+calver_format = "YYYY.QQ"
+versioned_files = [
+	"coding.go"
+]
+`)
+
+	_, err := configValues(".")
+	suite.NotNil(err)
+	suite.Equal("invalid calver_format: unknown token `QQ` in YYYY.QQ", fmt.Sprint(err))
+}
+
+func (suite *ConfigTestSuite) TestPreReleasesConfig() {
+	suite.writeFile(".dover", `[dover]
+pre_releases = [{name = "nightly", short = "n"}, "preview", "rc"]
+versioned_files = [
+	"coding.go"
+]
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal([]string{"nightly", "preview", "rc"}, cfg.scheme.releases().names())
+	suite.Equal("n", cfg.scheme.releases().shortName("nightly"))
+}
+
+func (suite *ConfigTestSuite) TestPreReleasesWithPep440Config() {
+	suite.writeFile(".dover", `[dover]
+version_scheme = "pep440"
+pre_releases = ["snapshot"]
+versioned_files = [
+	"coding.go"
+]
+`)
+
+	_, err := configValues(".")
+	suite.NotNil(err)
+	suite.Equal("pre_releases cannot be changed for the pep440 version scheme", fmt.Sprint(err))
+}
+
+func (suite *ConfigTestSuite) TestVersionedFileTablesConfig() {
+	suite.writeFile(".dover", `[dover]
+versioned_files = [
+	"coding.go",
+	{path = "overhill.go", search = "__version__ = \"{version}\""},
+	{path = "coding.go", search = "VERSION = \"{version}\"", replace = "VERSION = \"v{version}\""},
+	{path = "overhill.go", occurrence = 2},
+]
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal([]versionedFile{
+		{path: "coding.go"},
+		{path: "overhill.go", search: `__version__ = "{version}"`},
+		{path: "coding.go", search: `VERSION = "{version}"`, replace: `VERSION = "v{version}"`},
+		{path: "overhill.go", occurrence: 2},
+	}, cfg.files)
+}
+
+func (suite *ConfigTestSuite) TestInvalidVersionedFileTablesConfig() {
+	var tests = []struct {
+		entry, expected string
+	}{
+		{`{search = "VERSION = {version}"}`, ".dover: versioned_files entry has no path"},
+		{`{path = "coding.go", search = "VERSION = "}`, "coding.go: search pattern `VERSION = ` must contain {version} once"},
+		{`{path = "coding.go", search = "VERSION(={version})?"}`, "coding.go: search pattern `VERSION(={version})?` can match without a version, {version} can not be optional"},
+		{`{path = "coding.go", replace = "VERSION = {version}"}`, "coding.go: replace needs a search pattern"},
+		{`{path = "coding.go", search = "VERSION = {version}", replace = "VERSION"}`, "coding.go: replace template `VERSION` has no {version}"},
+		{`{path = "coding.go", occurrence = -1}`, "coding.go: occurrence must be 1 or more"},
+		{`{path = 3}`, ".dover: dover.versioned_files: path must be a string"},
+		{`{path = "coding.go", search = ["VERSION = {version}"]}`, ".dover: dover.versioned_files: search must be a string"},
+		{`{path = "coding.go", occurrence = "2"}`, ".dover: dover.versioned_files: occurrence must be an integer"},
+	}
+
+	for _, tt := range tests {
+		suite.writeFile(".dover", "[dover]\nversioned_files = ["+tt.entry+"]\n")
+		_, err := configValues(".")
+		suite.EqualError(err, tt.expected)
+	}
+}
+
+func (suite *ConfigTestSuite) TestKeyPathConfig() {
+	suite.writeFile("package.json", `{"version": "0.1.0"}`)
+	suite.writeFile(".dover", `[dover]
+versioned_files = ["package.json#version", "coding.go"]
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal(2, len(cfg.files))
+
+	suite.writeFile(".dover", `[dover]
+versioned_files = ["coding.go#version"]
+`)
+	_, err = configValues(".")
+	suite.EqualError(err, "coding.go: key paths are only supported for json, toml and yaml files")
+
+	suite.writeFile(".dover", `[dover]
+versioned_files = ["missing.json#version"]
+`)
+	_, err = configValues(".")
+	suite.EqualError(err, "no such file: missing.json")
+}
+
+func (suite *ConfigTestSuite) TestGlobConfig() {
+	suite.writeFile("packages/api/package.json", `{"version": "0.1.0"}`)
+	suite.writeFile("packages/web/package.json", `{"version": "0.1.0"}`)
+	suite.writeFile("packages/legacy/old/package.json", `{"version": "0.0.1"}`)
+	suite.writeFile(".dover", `[dover]
+versioned_files = [
+	"packages/**/package.json#version",
+	{path = "*.go:1", search = "VERSION = \"{version}\""},
+]
+exclude = ["packages/legacy/**"]
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal([]versionedFile{
+		{path: "packages/api/package.json#version"},
+		{path: "packages/web/package.json#version"},
+		{path: "coding.go:1", search: `VERSION = "{version}"`},
+		{path: "overhill.go:1", search: `VERSION = "{version}"`},
+	}, cfg.files)
+
+	suite.writeFile(".dover", `[dover]
+versioned_files = ["src/**/*.py"]
+`)
+	_, err = configValues(".")
+	suite.EqualError(err, ".dover: no files match src/**/*.py")
+
+	suite.writeFile(".dover", `[dover]
+versioned_files = ["*.go"]
+exclude = ["[a-"]
+`)
+	_, err = configValues(".")
+	suite.EqualError(err, ".dover: invalid glob pattern: [a-")
+}
+
+func (suite *ConfigTestSuite) TestCargoConfig() {
+	suite.writeFile("Cargo.toml", `[package]
+name = "coding"
+version = "0.1.0"
+
+[package.metadata.dover]
+version_format = "000.a0"
+versioned_files = ["Cargo.toml#package.version", "coding.go"]
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal("Cargo.toml", cfg.source)
+	suite.Equal("000.a0", cfg.format)
+	suite.Equal([]versionedFile{{path: "Cargo.toml#package.version"}, {path: "coding.go"}}, cfg.files)
+}
+
+func (suite *ConfigTestSuite) TestSetupCfgConfig() {
+	suite.writeFile("setup.cfg", `[metadata]
+name = overhill
+
+[dover]
+version_scheme = pep440
+versioned_files =
+    overhill.go
+    coding.go:1
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal("setup.cfg", cfg.source)
+	suite.Equal("pep440", cfg.schemeName)
+	suite.Equal([]versionedFile{{path: "overhill.go"}, {path: "coding.go:1"}}, cfg.files)
+}
+
+func (suite *ConfigTestSuite) TestYAMLConfig() {
+	suite.writeFile("dover.yaml", `dover:
+  version_format: 000.a0
+  pre_releases: [preview, {name: nightly, short: n}]
+  versioned_files:
+    - coding.go
+    - path: overhill.go
+      search: __version__ = "{version}"
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal("dover.yaml", cfg.source)
+	suite.Equal([]releaseLabel{{long: "preview"}, {long: "nightly", short: "n"}}, cfg.releases)
+	suite.Equal([]versionedFile{{path: "coding.go"}, {path: "overhill.go", search: `__version__ = "{version}"`}}, cfg.files)
+}
+
+func (suite *ConfigTestSuite) TestBumpversionConfig() {
+	suite.writeFile(".bumpversion.cfg", `[bumpversion]
+current_version = 0.1.0-a0
+commit = True
+allow_dirty = False
+message = Bump version: {current_version} -> {new_version}
+tag_name = release-{new_version}
+
+[bumpversion:file:coding.go]
+
+[bumpversion:file:overhill.go]
+search = __version__ = "{current_version}"
+replace = __version__ = "{new_version}"
+`)
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal(".bumpversion.cfg", cfg.source)
+	suite.Equal([]versionedFile{
+		{path: "coding.go", search: `(?P<version>0\.1\.0-a0)`},
+		{path: "overhill.go", search: `__version__ = "(?P<version>0\.1\.0-a0)"`, replace: `__version__ = "{version}"`},
+		{path: ".bumpversion.cfg", search: `^current_version\s*[=:]\s*(?P<version>0\.1\.0-a0)\s*$`},
+	}, cfg.files)
+	suite.Equal("Bump version: {current_version} -> {version}", cfg.commitMessage)
+	suite.Equal("release-{version}", cfg.tagName)
+	suite.Equal(CLEAN_TREE, cfg.requireClean)
+}
+
+func (suite *ConfigTestSuite) TestConfigPrecedence() {
+	suite.writeFile("setup.cfg", "[dover]\nversioned_files = overhill.go\n")
+	suite.writeFile("package.json", `{"name": "coding"}`)
+	suite.writeFile("pyproject.toml", "[tool.dover]\nversioned_files = [\"coding.go\"]\n")
+
+	cfg, err := configValues(".")
+	suite.Nil(err)
+	suite.Equal("pyproject.toml", cfg.source)
+	// package.json has no dover section
+	suite.Equal([]string{"setup.cfg"}, cfg.shadowed)
+}
+
 func TestRunConfigTestSuite(t *testing.T) {
 	suite.Run(t, new(ConfigTestSuite))
 }
+
+func TestDiscoverConfigValues(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	deep := filepath.Join(repo, "src", "deep")
+	assert.Nil(t, os.MkdirAll(deep, 0777))
+	assert.Nil(t, os.Mkdir(filepath.Join(repo, ".git"), 0777))
+	writeTestFiles(t, repo, map[string]string{
+		".dover":  "[dover]\nversioned_files = [\"main.go\"]\n",
+		"main.go": "const VERSION = \"1.2.0\"\n",
+	})
+
+	cfg, err := discoverConfigValues(deep)
+	assert.Nil(t, err)
+	assert.Equal(t, repo, cfg.dir)
+	assert.Equal(t, []versionedFile{{path: "main.go"}}, cfg.files)
+
+	// the search stops at the root of the git repository
+	assert.Nil(t, os.Rename(filepath.Join(repo, ".dover"), filepath.Join(root, ".dover")))
+	_, err = discoverConfigValues(deep)
+	assert.True(t, errors.Is(err, errConfigNotFound))
+}
+
+func TestNewConfigParser(t *testing.T) {
+	for _, configFile := range []string{".dover", "ci/dover.toml", "pyproject.toml", "package.json"} {
+		_, err := newConfigParser(configFile)
+		assert.Nil(t, err, configFile)
+	}
+	_, err := newConfigParser("dover.ini")
+	assert.EqualError(t, err, "unknown config file type: dover.ini")
+}
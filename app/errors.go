@@ -0,0 +1,169 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Exit codes used by the dover command.
+const (
+	EXIT_OK                    = 0
+	EXIT_ERROR                 = 1
+	EXIT_USAGE                 = 2
+	EXIT_CONFIG_ERROR          = 3
+	EXIT_PARSE_ERROR           = 4
+	EXIT_INCONSISTENT_VERSIONS = 5
+	EXIT_INVALID_FORMAT        = 6
+	EXIT_RELEASE_ORDER         = 7
+	EXIT_GIT_ERROR             = 8
+)
+
+// UsageError is a command line that asks for something dover can not do,
+// e.g. options that can not be used together or with the project's version
+// scheme.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError is a problem with the dover configuration. File is the config
+// file the problem was found in, when there is one.
+type ConfigError struct {
+	File string
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	if e.File == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is text that could not be read as the Kind of thing expected,
+// e.g. a `version` or a `calver version`.
+type ParseError struct {
+	Kind   string
+	Text   string
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("invalid %s: %s", e.Kind, e.Text)
+	if e.Reason != "" {
+		msg += " " + e.Reason
+	}
+	return msg
+}
+
+// InconsistentVersionError is returned when the versioned files do not all
+// have the same version. Matches are all the versions found, and Reason says
+// why they can not be brought in line, when that was tried.
+type InconsistentVersionError struct {
+	Matches []*VersionMatch
+	Reason  string
+}
+
+func (e *InconsistentVersionError) Error() string {
+	if e.Reason != "" {
+		return e.Reason
+	}
+	return "versions do not match across all files"
+}
+
+// NoVersionError is returned when none of the versioned Files has a version
+// string, which is a problem with the configuration as much as the files.
+type NoVersionError struct {
+	Files []string
+}
+
+func (e *NoVersionError) Error() string {
+	return "no version strings found in the versioned files"
+}
+
+// InvalidFormatError is a version format string that could not be read.
+type InvalidFormatError struct {
+	Format string
+}
+
+func (e *InvalidFormatError) Error() string {
+	return fmt.Sprintf("invalid version format: %s", e.Format)
+}
+
+// ReleaseOrderError is a pre-release that is not on the release ladder
+// (Expected lists the ones that are) or that comes before the current one.
+type ReleaseOrderError struct {
+	Current   string
+	Requested string
+	Expected  []string
+}
+
+func (e *ReleaseOrderError) Error() string {
+	if e.Expected != nil {
+		return fmt.Sprintf("Unknown pre-release `%s`. Expected one of: %s.", e.Requested, strings.Join(e.Expected, ", "))
+	}
+	return fmt.Sprintf("Invalid release order requested. `%s` comes before the current release `%s`.", e.Requested, e.Current)
+}
+
+// GitError is a git command that failed, or a git repository dover will not
+// commit or tag in as it is.
+type GitError struct {
+	Command string
+	Err     error
+}
+
+func (e *GitError) Error() string {
+	if e.Command == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("git %s: %s", e.Command, e.Err)
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// exitCode maps an error to the dover command's exit code.
+func exitCode(err error) int {
+	var (
+		configError       *ConfigError
+		parseError        *ParseError
+		inconsistentError *InconsistentVersionError
+		formatError       *InvalidFormatError
+		releaseOrderError *ReleaseOrderError
+		gitError          *GitError
+		noVersionError    *NoVersionError
+		usageError        *UsageError
+	)
+	switch {
+	case err == nil:
+		return EXIT_OK
+	case errors.As(err, &inconsistentError):
+		return EXIT_INCONSISTENT_VERSIONS
+	case errors.As(err, &releaseOrderError):
+		return EXIT_RELEASE_ORDER
+	case errors.As(err, &formatError):
+		return EXIT_INVALID_FORMAT
+	case errors.As(err, &parseError):
+		return EXIT_PARSE_ERROR
+	case errors.As(err, &configError), errors.As(err, &noVersionError):
+		return EXIT_CONFIG_ERROR
+	case errors.As(err, &gitError):
+		return EXIT_GIT_ERROR
+	case errors.As(err, &usageError):
+		return EXIT_USAGE
+	}
+	return EXIT_ERROR
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/marco-m/docopt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, EXIT_OK},
+		{errors.New("boom"), EXIT_ERROR},
+		{&ConfigError{Err: errors.New("unable to find dover configuration")}, EXIT_CONFIG_ERROR},
+		{&ConfigError{File: ".dover", Err: &InvalidFormatError{Format: "0X"}}, EXIT_INVALID_FORMAT},
+		{&ParseError{Kind: "version", Text: "1.x"}, EXIT_PARSE_ERROR},
+		{&InconsistentVersionError{}, EXIT_INCONSISTENT_VERSIONS},
+		{&NoVersionError{Files: []string{"main.go"}}, EXIT_CONFIG_ERROR},
+		{&InvalidFormatError{Format: "0X"}, EXIT_INVALID_FORMAT},
+		{&ReleaseOrderError{Current: "beta", Requested: "alpha"}, EXIT_RELEASE_ORDER},
+		{fmt.Errorf("bumping: %w", &ReleaseOrderError{Current: "beta", Requested: "alpha"}), EXIT_RELEASE_ORDER},
+		{&GitError{Command: "commit", Err: errors.New("exit status 1")}, EXIT_GIT_ERROR},
+		{&UsageError{Err: errors.New("--tag needs --commit")}, EXIT_USAGE},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.err), func(t *testing.T) {
+			assert.Equal(t, tt.expected, exitCode(tt.err))
+		})
+	}
+}
+
+func TestErrorMessages(t *testing.T) {
+	assert.EqualError(t, &ConfigError{File: "package.json", Err: errors.New("json parsing failed")}, "package.json: json parsing failed")
+	assert.EqualError(t, &ParseError{Kind: "version", Text: "01.2.3", Reason: "has a leading zero"}, "invalid version: 01.2.3 has a leading zero")
+	assert.EqualError(t, &InvalidFormatError{Format: "0X"}, "invalid version format: 0X")
+	assert.EqualError(t, &ReleaseOrderError{Requested: "gamma", Expected: []string{"alpha", "beta"}}, "Unknown pre-release `gamma`. Expected one of: alpha, beta.")
+	assert.EqualError(t, &GitError{Command: "tag v1.0.0", Err: errors.New("tag 'v1.0.0' already exists")}, "git tag v1.0.0: tag 'v1.0.0' already exists")
+}
+
+func TestReturnedErrorTypes(t *testing.T) {
+	_, err := NewVersionFormater("0X")
+	assert.IsType(t, &InvalidFormatError{}, err)
+
+	_, err = parseVersion("1.x")
+	assert.IsType(t, &ParseError{}, err)
+
+	_, _, err = parseVersionedFileConfig("main.go:one")
+	assert.IsType(t, &ParseError{}, err)
+
+	v := NewVersion([]string{"1", "2", "3", "beta", "0"})
+	_, err = v.bump("", "alpha")
+	assert.IsType(t, &ReleaseOrderError{}, err)
+}
+
+func TestFilterFlags(t *testing.T) {
+	flag, err := filterFlags(map[string]any{"--major": true, "--minor": false}, []string{"major", "minor"})
+	assert.Nil(t, err)
+	assert.Equal(t, "major", flag)
+
+	_, err = filterFlags(map[string]any{"--major": true, "--minor": true}, []string{"major", "minor"})
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+
+	flag, err = filterFlags(map[string]any{"--pre": "preview", "--alpha": false}, []string{"pre", "alpha"})
+	assert.Nil(t, err)
+	assert.Equal(t, "pre", flag)
+
+	_, err = filterFlags(map[string]any{"--pre": "preview", "--alpha": true}, []string{"pre", "alpha"})
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+
+	flag, err = filterFlags(map[string]any{"--pre": nil, "--alpha": true}, []string{"pre", "alpha"})
+	assert.Nil(t, err)
+	assert.Equal(t, "alpha", flag)
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := []docopt.Opts{
+		{"--tag": true, "--commit": false},
+		{"--output": "xml"},
+		{"--major": true, "--minor": true},
+		{"--alpha": true, "--post": true},
+		{"--highest": true, "--majority": true},
+	}
+	for _, opts := range tests {
+		t.Run(fmt.Sprint(opts), func(t *testing.T) {
+			_, err := compileArguments(opts)
+			assert.Equal(t, EXIT_USAGE, exitCode(err))
+		})
+	}
+
+	semver := NewVersion([]string{"1", "2", "3", "", ""})
+	_, err := semver.bump("", "post")
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+	_, err = semver.bump("calver", "")
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+
+	pep440, _, _ := pep440Scheme{}.parse("1.2.3")
+	_, err = pep440.bump("calver", "")
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+
+	calver := parseCalver(t, newTestCalverScheme(t, "YYYY.0M", 2026, time.October, 18), "2026.09")
+	_, err = calver.bump("major", "")
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+	_, err = calver.bump("patch", "")
+	assert.Equal(t, EXIT_USAGE, exitCode(err))
+}
@@ -1,12 +1,13 @@
 package app
 
-import (
-	"os"
-)
 import "regexp"
-import "fmt"
 import "strings"
 
+const (
+	DEFAULT_FORMAT   = "000.A.0"
+	CANONICAL_FORMAT = "000-A.0"
+)
+
 type Formatter struct {
 	versionFormat    string
 	releaseSeparator string
@@ -32,30 +33,55 @@ func (f *Formatter) format(v *Version) string {
 		output = append(output, v.patch)
 	}
 
+	output = append(output, f.formatPreRelease(v))
+
+	return strings.Join(output, "")
+}
+
+// formatPreRelease writes everything that follows the numeric part of the
+// version: the pre-release and the build metadata.
+func (f *Formatter) formatPreRelease(v *Version) string {
+	output := []string{}
+
 	if v.release != "" {
 
 		output = append(output, f.releaseSeparator)
 
 		switch f.releaseFormat {
 		case "a":
-			output = append(output, SHORT[v.release])
+			output = append(output, v.releases().shortName(v.release))
 		case "A":
-			output = append(output, LONG[v.release])
+			output = append(output, v.releases().longName(v.release))
 		}
 
-		if f.buildFormat == "0" {
+		if f.buildFormat == "0" && v.build != "" {
 			output = append(output, f.buildSeparator)
 			output = append(output, v.build)
 		}
 
 	}
 
+	if len(v.identifiers) > 0 {
+		// remaining identifiers are always written the SemVer way
+		if v.release == "" {
+			output = append(output, "-")
+		} else {
+			output = append(output, ".")
+		}
+		output = append(output, strings.Join(v.identifiers, "."))
+	}
+
+	if len(v.metadata) > 0 {
+		output = append(output, "+")
+		output = append(output, strings.Join(v.metadata, "."))
+	}
+
 	return strings.Join(output, "")
 }
 
 var FORMAT_REGEX string = `^(000)([^a-zA-ZA\d])?([aA])?([^a-zA-Z\d])?(0)?$`
 
-func NewVersionFormater(versionFormatString string) *Formatter {
+func NewVersionFormater(versionFormatString string) (*Formatter, error) {
 	/// The format string consists of 5 parts:
 	///  The numeric version format 000. Periods are assumed and there must be 3 zeros.
 	///  The release separator - could be anything or nothing as long as it's not alphanumeric and it's a single character
@@ -63,14 +89,12 @@ func NewVersionFormater(versionFormatString string) *Formatter {
 	///  The build separator - could be anything or nothing as long as its not alphanumeric and it's a single character.
 	///  The build number - this is either 0 or nothing.
 
-	rx, err := regexp.Compile(FORMAT_REGEX)
-	check(err)
+	rx := regexp.MustCompile(FORMAT_REGEX)
 
 	match := rx.FindStringSubmatch(versionFormatString)
 
 	if match == nil {
-		fmt.Printf("Invalid version format: %s", versionFormatString)
-		os.Exit(1)
+		return nil, &InvalidFormatError{Format: versionFormatString}
 	}
 
 	format := Formatter{
@@ -81,5 +105,5 @@ func NewVersionFormater(versionFormatString string) *Formatter {
 		buildFormat:      match[5],
 	}
 
-	return &format
+	return &format, nil
 }
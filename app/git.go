@@ -0,0 +1,335 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const GIT_BINARY = "git"
+
+// What require_clean asks to be free of uncommitted changes before the
+// versioned files are rewritten.
+const (
+	CLEAN_TREE            = "tree"
+	CLEAN_VERSIONED_FILES = "versioned_files"
+)
+
+var CLEAN_CHECKS = []string{"", CLEAN_TREE, CLEAN_VERSIONED_FILES}
+
+// The commit message and tag templates used when the config has none.
+const (
+	DEFAULT_COMMIT_MESSAGE     = "Bump version to {version}"
+	DEFAULT_TAG_NAME           = "v{version}"
+	DEFAULT_COMPONENT_TAG_NAME = "{name}-v{version}"
+)
+
+// Placeholders of the commit message and tag templates, on top of the
+// {version} the plan bumps to.
+const (
+	CURRENT_VERSION_PLACEHOLDER = "{current_version}"
+	NAME_PLACEHOLDER            = "{name}"
+)
+
+// runGit runs git in dir and returns what it wrote to stdout. When git
+// fails the error is what it wrote to stderr.
+func runGit(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(GIT_BINARY, args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", &GitError{Command: args[0], Err: errors.New(message)}
+	}
+	return stdout.String(), nil
+}
+
+// gitPrefix is the path of dir from the root of its git repository, e.g.
+// `services/api/`, or an empty string at the root.
+func gitPrefix(dir string) (string, error) {
+	prefix, err := runGit(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", &GitError{Err: fmt.Errorf("%s is not in a git repository", dir)}
+	}
+	return strings.TrimSpace(prefix), nil
+}
+
+// gitStagedFiles lists the files staged for the next commit, by their path
+// from the root of the repository.
+func gitStagedFiles(dir string) ([]string, error) {
+	output, err := runGit(dir, "diff", "--cached", "--name-only", "-z")
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// gitDirtyFiles lists the files with uncommitted changes, staged or not, by
+// their path from the root of the repository. Given paths, only those files
+// are looked at. Untracked files are left out.
+func gitDirtyFiles(dir string, paths ...string) ([]string, error) {
+	args := []string{"status", "--porcelain", "-z", "--untracked-files=no"}
+	if len(paths) > 0 {
+		args = append(args, "--")
+		for _, file := range paths {
+			args = append(args, ":(literal)"+file)
+		}
+	}
+	output, err := runGit(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// XY path, followed by the original path of a rename or copy
+	files := []string{}
+	entries := strings.Split(output, "\x00")
+	for index := 0; index < len(entries); index++ {
+		entry := entries[index]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, entry[3:])
+		if entry[0] == 'R' || entry[0] == 'C' {
+			index++
+		}
+	}
+	return files, nil
+}
+
+// gitBranch is the name of the checked out branch, or an empty string when
+// HEAD is detached.
+func gitBranch(dir string) string {
+	branch, err := runGit(dir, "symbolic-ref", "--short", "--quiet", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(branch)
+}
+
+func gitTagExists(dir string, tag string) bool {
+	_, err := runGit(dir, "rev-parse", "--quiet", "--verify", "refs/tags/"+tag)
+	return err == nil
+}
+
+// changedFiles lists the files the plan changes, once each, relative to the
+// project directory.
+func changedFiles(plan *Plan) []string {
+	files := []string{}
+	for _, change := range plan.Changes {
+		if IndexOf(&files, change.Match.file) == -1 {
+			files = append(files, change.Match.file)
+		}
+	}
+	if plan.Changelog != nil && IndexOf(&files, plan.Changelog.File) == -1 {
+		files = append(files, plan.Changelog.File)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// commitFiles are the files to commit for the plan, relative to the project
+// directory. A versioned file that is a symlink is rewritten through the
+// link, so it is the file the link points to that is committed.
+func (p *Project) commitFiles(plan *Plan) ([]string, error) {
+	dir, err := filepath.EvalSymlinks(p.config.dir)
+	if err != nil {
+		return nil, err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, file := range changedFiles(plan) {
+		target, err := filepath.EvalSymlinks(projectPath(p.config.dir, file))
+		if err != nil {
+			return nil, err
+		}
+		target, err = filepath.Abs(target)
+		if err != nil {
+			return nil, err
+		}
+		target, err = filepath.Rel(dir, target)
+		if err != nil {
+			return nil, err
+		}
+		if IndexOf(&files, target) == -1 {
+			files = append(files, target)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// releaseText fills in the placeholders of a commit message or tag template.
+func (p *Project) releaseText(template string, plan *Plan) string {
+	current := ""
+	if plan.Current != nil {
+		current = plan.Current.format(plan.Bump.Format)
+	}
+	return strings.NewReplacer(
+		VERSION_PLACEHOLDER, plan.Next.format(plan.Bump.Format),
+		CURRENT_VERSION_PLACEHOLDER, current,
+		NAME_PLACEHOLDER, p.config.name,
+	).Replace(template)
+}
+
+// tagTemplate is the config's tag_name, or else the default for a project
+// or a component.
+func (p *Project) tagTemplate() string {
+	if p.config.tagName != "" {
+		return p.config.tagName
+	}
+	if p.config.name != "" {
+		return DEFAULT_COMPONENT_TAG_NAME
+	}
+	return DEFAULT_TAG_NAME
+}
+
+func (p *Project) tagName(plan *Plan) string {
+	return p.releaseText(p.tagTemplate(), plan)
+}
+
+// CheckCommit makes sure the plan can be committed, and with tag tagged, once
+// it is applied: the project has to be in a git repository with nothing but
+// the files the plan changes staged, and the tag can not exist yet.
+func (p *Project) CheckCommit(plan *Plan, tag bool) error {
+	prefix, err := gitPrefix(p.config.dir)
+	if err != nil {
+		return err
+	}
+
+	files, err := p.commitFiles(plan)
+	if err != nil {
+		return err
+	}
+	changed := []string{}
+	for _, file := range files {
+		changed = append(changed, path.Join(prefix, filepath.ToSlash(file)))
+	}
+	staged, err := gitStagedFiles(p.config.dir)
+	if err != nil {
+		return err
+	}
+	unrelated := []string{}
+	for _, file := range staged {
+		if IndexOf(&changed, file) == -1 {
+			unrelated = append(unrelated, file)
+		}
+	}
+	if len(unrelated) > 0 {
+		return &GitError{Err: fmt.Errorf("unrelated changes are staged, commit or unstage them first: %s", strings.Join(unrelated, ", "))}
+	}
+
+	if tag && gitTagExists(p.config.dir, p.tagName(plan)) {
+		return &GitError{Err: fmt.Errorf("the tag %s already exists", p.tagName(plan))}
+	}
+	return nil
+}
+
+// Commit stages the files changed by an applied plan and commits them, with
+// the config's commit_message.
+func (p *Project) Commit(plan *Plan) error {
+	err := p.CheckCommit(plan, false)
+	if err != nil {
+		return err
+	}
+
+	files, err := p.commitFiles(plan)
+	if err != nil {
+		return err
+	}
+	_, err = runGit(p.config.dir, append([]string{"add", "--"}, files...)...)
+	if err != nil {
+		return err
+	}
+
+	message := p.config.commitMessage
+	if message == "" {
+		message = DEFAULT_COMMIT_MESSAGE
+	}
+	_, err = runGit(p.config.dir, "commit", "--message", p.releaseText(message, plan))
+	return err
+}
+
+// Tag tags the commit of an applied plan and returns the tag's name. The tag
+// is annotated when the config has a tag_message.
+func (p *Project) Tag(plan *Plan) (string, error) {
+	tag := p.tagName(plan)
+	args := []string{"tag", tag}
+	if p.config.tagMessage != "" {
+		args = append(args, "--annotate", "--message", p.releaseText(p.config.tagMessage, plan))
+	}
+	_, err := runGit(p.config.dir, args...)
+	return tag, err
+}
+
+// Preflight makes sure the git repository is ready for the versioned files
+// to be rewritten, as the config's require_clean and allowed_branches ask:
+// free of uncommitted changes, in the whole tree or just the versioned
+// files, and on an allowed branch. allowDirty skips the require_clean check.
+func (p *Project) Preflight(allowDirty bool) error {
+	requireClean := p.config.requireClean
+	if allowDirty {
+		requireClean = ""
+	}
+	if requireClean == "" && len(p.config.branches) == 0 {
+		return nil
+	}
+
+	dir := p.config.dir
+	if _, err := gitPrefix(dir); err != nil {
+		return err
+	}
+
+	if len(p.config.branches) > 0 {
+		branch := gitBranch(dir)
+		allowed := false
+		for _, pattern := range p.config.branches {
+			if branch != "" && matchGlob(pattern, branch) {
+				allowed = true
+			}
+		}
+		branches := strings.Join(p.config.branches, ", ")
+		if !allowed && branch == "" {
+			return &GitError{Err: fmt.Errorf("HEAD is detached, versions are only bumped on %s", branches)}
+		}
+		if !allowed {
+			return &GitError{Err: fmt.Errorf("on branch %s, versions are only bumped on %s", branch, branches)}
+		}
+	}
+
+	var dirty []string
+	var err error
+	switch requireClean {
+	case CLEAN_TREE:
+		dirty, err = gitDirtyFiles(dir)
+	case CLEAN_VERSIONED_FILES:
+		dirty, err = gitDirtyFiles(dir, versionedFilePaths(p.config.files)...)
+	}
+	if err != nil {
+		return err
+	}
+	if len(dirty) > 0 {
+		return &GitError{Err: fmt.Errorf("uncommitted changes in %s, commit them first or use --allow-dirty", strings.Join(dirty, ", "))}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GitTestSuite struct {
+	suite.Suite
+	tempDir string
+}
+
+func (suite *GitTestSuite) writeFile(name, content string) {
+	file := filepath.Join(suite.tempDir, name)
+	suite.Nil(os.MkdirAll(filepath.Dir(file), 0777))
+	suite.Nil(os.WriteFile(file, []byte(content), 0666))
+}
+
+func (suite *GitTestSuite) git(args ...string) string {
+	output, err := runGit(suite.tempDir, args...)
+	suite.Nil(err)
+	return strings.TrimSpace(output)
+}
+
+func (suite *GitTestSuite) SetupTest() {
+	if _, err := exec.LookPath(GIT_BINARY); err != nil {
+		suite.T().Skip("git is not installed")
+	}
+	suite.tempDir, _ = os.MkdirTemp("", "gotest-*")
+	suite.writeFile("api/.dover", `[dover]
+version_format = "000"
+versioned_files = ["main.go", "version.txt"]
+`)
+	suite.writeFile("api/main.go", "package main\n\nconst VERSION = \"1.2.0\"\n")
+	suite.writeFile("api/version.txt", "version 1.2.0\n")
+	suite.writeFile("README.md", "# api\n")
+
+	suite.git("init", "--quiet")
+	suite.git("symbolic-ref", "HEAD", "refs/heads/main")
+	suite.git("config", "user.name", "dover")
+	suite.git("config", "user.email", "dover@example.com")
+	suite.git("config", "commit.gpgsign", "false")
+	suite.git("config", "tag.gpgsign", "false")
+	suite.git("add", "--all")
+	suite.git("commit", "--quiet", "--message", "Initial commit")
+}
+
+func (suite *GitTestSuite) TearDownTest() {
+	os.RemoveAll(suite.tempDir)
+}
+
+func (suite *GitTestSuite) plan(part string) (*Project, *Plan) {
+	project, err := LoadProject(filepath.Join(suite.tempDir, "api"))
+	suite.Nil(err)
+	plan, err := project.Plan(Bump{Part: part})
+	suite.Nil(err)
+	return project, plan
+}
+
+func (suite *GitTestSuite) TestCommitAndTag() {
+	project, plan := suite.plan("minor")
+	suite.Nil(project.CheckCommit(plan, true))
+	suite.Nil(project.Apply(plan))
+	suite.Nil(project.Commit(plan))
+
+	tag, err := project.Tag(plan)
+	suite.Nil(err)
+	suite.Equal("v1.3.0", tag)
+
+	suite.Equal("Bump version to 1.3.0", suite.git("log", "-1", "--format=%s"))
+	suite.Equal("api/main.go\napi/version.txt", suite.git("show", "--name-only", "--format=", "HEAD"))
+	suite.Equal("commit", suite.git("cat-file", "-t", "v1.3.0"))
+	suite.Equal("", suite.git("status", "--porcelain"))
+}
+
+func (suite *GitTestSuite) TestCommitSymlinkedVersionedFile() {
+	suite.writeFile("VERSION", "version 1.2.0\n")
+	suite.Nil(os.Remove(filepath.Join(suite.tempDir, "api/version.txt")))
+	suite.Nil(os.Symlink("../VERSION", filepath.Join(suite.tempDir, "api/version.txt")))
+	suite.git("add", "--all")
+	suite.git("commit", "--quiet", "--message", "Link the version")
+
+	project, plan := suite.plan("minor")
+	suite.Nil(project.CheckCommit(plan, true))
+	suite.Nil(project.Apply(plan))
+	suite.Nil(project.Commit(plan))
+
+	suite.Equal("VERSION\napi/main.go", suite.git("show", "--name-only", "--format=", "HEAD"))
+	suite.Equal("version 1.3.0", suite.git("show", "HEAD:VERSION"))
+	suite.Equal("", suite.git("status", "--porcelain"))
+}
+
+func (suite *GitTestSuite) TestCommitTemplates() {
+	suite.writeFile("api/.dover", `[dover]
+version_format = "000"
+versioned_files = ["main.go", "version.txt"]
+commit_message = "Release {current_version} -> {version}"
+tag_name = "api/{version}"
+tag_message = "api {version}"
+`)
+	suite.git("commit", "--quiet", "--all", "--message", "Configure dover")
+
+	project, plan := suite.plan("patch")
+	suite.Nil(project.Apply(plan))
+	suite.Nil(project.Commit(plan))
+	tag, err := project.Tag(plan)
+	suite.Nil(err)
+
+	suite.Equal("Release 1.2.0 -> 1.2.1", suite.git("log", "-1", "--format=%s"))
+	suite.Equal("api/1.2.1", tag)
+	suite.Equal("tag", suite.git("cat-file", "-t", "api/1.2.1"))
+	suite.Equal("api 1.2.1", suite.git("tag", "--list", "--format=%(contents:subject)", "api/1.2.1"))
+}
+
+func (suite *GitTestSuite) TestComponentTagName() {
+	project, plan := suite.plan("major")
+	suite.Equal("v2.0.0", project.tagName(plan))
+
+	project.config.name = "api"
+	suite.Equal("api-v2.0.0", project.tagName(plan))
+}
+
+func (suite *GitTestSuite) TestCheckCommit() {
+	project, plan := suite.plan("minor")
+
+	// staged changes to the versioned files themselves are fine
+	suite.writeFile("api/version.txt", "version 1.2.0\n\n")
+	suite.git("add", "api/version.txt")
+	suite.Nil(project.CheckCommit(plan, true))
+
+	suite.writeFile("README.md", "# api v1\n")
+	suite.git("add", "README.md")
+	err := project.CheckCommit(plan, true)
+	suite.IsType(&GitError{}, err)
+	suite.Equal("unrelated changes are staged, commit or unstage them first: README.md", fmt.Sprint(err))
+
+	suite.git("reset", "--quiet", "README.md")
+	suite.git("tag", "v1.3.0")
+	err = project.CheckCommit(plan, false)
+	suite.Nil(err)
+	err = project.CheckCommit(plan, true)
+	suite.Equal("the tag v1.3.0 already exists", fmt.Sprint(err))
+}
+
+func (suite *GitTestSuite) TestCheckCommitOutsideRepository() {
+	dir, _ := os.MkdirTemp("", "gotest-*")
+	defer os.RemoveAll(dir)
+	os.WriteFile(filepath.Join(dir, ".dover"), []byte("[dover]\nversioned_files = [\"VERSION\"]\n"), 0666)
+	os.WriteFile(filepath.Join(dir, "VERSION"), []byte("version 1.2.0\n"), 0666)
+
+	project, err := LoadProject(dir)
+	suite.Nil(err)
+	plan, err := project.Plan(Bump{Part: "minor"})
+	suite.Nil(err)
+
+	err = project.CheckCommit(plan, false)
+	suite.IsType(&GitError{}, err)
+	suite.Equal(fmt.Sprintf("%s is not in a git repository", dir), fmt.Sprint(err))
+}
+
+func (suite *GitTestSuite) TestPreflightRequireClean() {
+	project, _ := suite.plan("minor")
+	suite.writeFile("README.md", "# api v1\n")
+	suite.writeFile("notes.txt", "untracked\n")
+	suite.Nil(project.Preflight(false))
+
+	project.config.requireClean = CLEAN_TREE
+	err := project.Preflight(false)
+	suite.IsType(&GitError{}, err)
+	suite.Equal("uncommitted changes in README.md, commit them first or use --allow-dirty", fmt.Sprint(err))
+	suite.Nil(project.Preflight(true))
+
+	project.config.requireClean = CLEAN_VERSIONED_FILES
+	suite.Nil(project.Preflight(false))
+
+	suite.writeFile("api/version.txt", "version 1.2.0\n\n")
+	err = project.Preflight(false)
+	suite.Equal("uncommitted changes in api/version.txt, commit them first or use --allow-dirty", fmt.Sprint(err))
+}
+
+func (suite *GitTestSuite) TestPreflightAllowedBranches() {
+	project, _ := suite.plan("minor")
+	project.config.branches = []string{"main", "release/*"}
+	suite.Nil(project.Preflight(false))
+
+	suite.git("checkout", "--quiet", "-b", "release/1.3")
+	suite.Nil(project.Preflight(false))
+
+	suite.git("checkout", "--quiet", "-b", "feature/login")
+	err := project.Preflight(true)
+	suite.IsType(&GitError{}, err)
+	suite.Equal("on branch feature/login, versions are only bumped on main, release/*", fmt.Sprint(err))
+
+	suite.git("checkout", "--quiet", "--detach")
+	err = project.Preflight(false)
+	suite.Equal("HEAD is detached, versions are only bumped on main, release/*", fmt.Sprint(err))
+}
+
+func TestRunGitTestSuite(t *testing.T) {
+	suite.Run(t, new(GitTestSuite))
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const GLOB_CHARS = "*?[{"
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, GLOB_CHARS)
+}
+
+// expandBraces turns `{a,b}` alternatives into one pattern each, e.g.
+// `charts/{api,web}/Chart.yaml` into `charts/api/Chart.yaml` and
+// `charts/web/Chart.yaml`.
+func expandBraces(pattern string) []string {
+	start := strings.Index(pattern, "{")
+	if start == -1 {
+		return []string{pattern}
+	}
+
+	depth, end := 0, -1
+	alternatives := []string{}
+	last := start + 1
+	for index := start; index < len(pattern) && end == -1; index++ {
+		switch pattern[index] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				end = index
+				alternatives = append(alternatives, pattern[last:index])
+			}
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[last:index])
+				last = index + 1
+			}
+		}
+	}
+	if end == -1 {
+		return []string{pattern}
+	}
+
+	patterns := []string{}
+	for _, alternative := range alternatives {
+		patterns = append(patterns, expandBraces(pattern[:start]+alternative+pattern[end+1:])...)
+	}
+	return patterns
+}
+
+// matchGlob matches a slash separated name against a glob pattern. On top
+// of the `*`, `?` and `[...]` of path.Match, a `**` segment matches any
+// number of directories and `{a,b}` matches either alternative.
+func matchGlob(pattern string, name string) bool {
+	for _, pattern := range expandBraces(pattern) {
+		if matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlobSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for index := 0; index <= len(name); index++ {
+				if matchGlobSegments(pattern[1:], name[index:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		matched, err := path.Match(pattern[0], name[0])
+		if err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+func validateGlob(pattern string) error {
+	for _, pattern := range expandBraces(pattern) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob pattern: %s", pattern)
+		}
+	}
+	return nil
+}
+
+// globRoot is the directory part of the pattern before its first glob.
+func globRoot(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for index, segment := range segments {
+		if isGlob(segment) {
+			return path.Join(segments[:index]...)
+		}
+	}
+	return path.Dir(pattern)
+}
+
+// expandGlob lists the files under dir, as slash separated paths relative
+// to dir, that match the pattern and none of the excludes.
+func expandGlob(dir string, pattern string, excludes []string) ([]string, error) {
+	if err := validateGlob(pattern); err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	root := filepath.Join(dir, filepath.FromSlash(globRoot(pattern)))
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		if !matchGlob(pattern, name) {
+			return nil
+		}
+		for _, exclude := range excludes {
+			if matchGlob(exclude, name) {
+				return nil
+			}
+		}
+		files = append(files, name)
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// expandVersionedFiles replaces every versioned file whose path is a glob
+// with one entry for each file it matches. The :line notation and key path
+// of the entry carry over to every file.
+func expandVersionedFiles(dir string, files []versionedFile, excludes []string) ([]versionedFile, error) {
+	for _, exclude := range excludes {
+		if err := validateGlob(exclude); err != nil {
+			return nil, err
+		}
+	}
+
+	expanded := []versionedFile{}
+	for _, file := range files {
+		filePath, keyPath := splitFileAndKeyPath(file.path)
+		filePath, lineNotation := splitFileAndLineNotation(filePath)
+		if !isGlob(filePath) {
+			expanded = append(expanded, file)
+			continue
+		}
+
+		suffix := ""
+		if lineNotation != "" {
+			suffix += LINE_NOTATION_SEPARATOR + lineNotation
+		}
+		if keyPath != "" {
+			suffix += KEY_PATH_SEPARATOR + keyPath
+		}
+
+		matches, err := expandGlob(dir, filePath, excludes)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", filePath)
+		}
+		for _, match := range matches {
+			globbed := file
+			globbed.path = match + suffix
+			expanded = append(expanded, globbed)
+		}
+	}
+	return expanded, nil
+}
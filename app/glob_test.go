@@ -0,0 +1,37 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "app/main.go", false},
+		{"app/*.go", "app/main.go", true},
+		{"**/package.json", "package.json", true},
+		{"packages/**/package.json", "packages/api/package.json", true},
+		{"packages/**/package.json", "packages/api/web/package.json", true},
+		{"packages/**/package.json", "package.json", false},
+		{"packages/**", "packages/api/package.json", true},
+		{"charts/{api,web}/Chart.yaml", "charts/web/Chart.yaml", true},
+		{"charts/{api,web}/Chart.yaml", "charts/db/Chart.yaml", false},
+		{"v?.txt", "v1.txt", true},
+		{"[ab].go", "c.go", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, matchGlob(tt.pattern, tt.name), tt.pattern+" "+tt.name)
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	assert.Equal(t, []string{"a.go"}, expandBraces("a.go"))
+	assert.Equal(t, []string{"a/x.go", "a/y.go"}, expandBraces("a/{x,y}.go"))
+	assert.Equal(t, []string{"a.json", "b.toml", "b.yaml"}, expandBraces("{a.json,b.{toml,yaml}}"))
+}
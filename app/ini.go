@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// iniSection is a `[name]` section of an ini file such as setup.cfg, with
+// its keys in the order they appear.
+type iniSection struct {
+	name   string
+	keys   []string
+	values map[string]string
+}
+
+func (s *iniSection) get(key string) string {
+	return s.values[key]
+}
+
+// getList splits a value into one item per line, or per word when the value
+// is on a single line.
+func (s *iniSection) getList(key string) []string {
+	value := strings.TrimSpace(s.values[key])
+	if !strings.Contains(value, "\n") {
+		return strings.Fields(value)
+	}
+	items := []string{}
+	for _, item := range strings.Split(value, "\n") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseINI reads an ini file the way python's configparser does: `key =
+// value` or `key: value` pairs under `[section]` headers, with indented lines
+// continuing the value before them and `#` or `;` starting a comment line.
+func parseINI(content string) ([]*iniSection, error) {
+	sections := []*iniSection{}
+	var section *iniSection
+	key := ""
+	for index, line := range parseTextFile([]byte(content)).lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+
+		if key != "" && (line[0] == ' ' || line[0] == '\t') {
+			section.values[key] += "\n" + trimmed
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = &iniSection{name: strings.TrimSpace(trimmed[1 : len(trimmed)-1]), values: map[string]string{}}
+			sections = append(sections, section)
+			key = ""
+			continue
+		}
+
+		separator := strings.IndexAny(trimmed, "=:")
+		if section == nil || separator == -1 {
+			return nil, fmt.Errorf("line %d: expected a [section] or key = value", index+1)
+		}
+		key = strings.ToLower(strings.TrimSpace(trimmed[:separator]))
+		section.keys = append(section.keys, key)
+		section.values[key] = strings.TrimSpace(trimmed[separator+1:])
+	}
+	return sections, nil
+}
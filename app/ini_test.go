@@ -0,0 +1,47 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const SETUP_CFG = `[metadata]
+name = overhill
+
+# dover settings
+[dover]
+version_format: 000.r0
+versioned_files =
+    overhill.py
+    README.md:1-3
+pre_releases = alpha beta
+`
+
+func TestParseINI(t *testing.T) {
+	sections, err := parseINI(SETUP_CFG)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(sections))
+	assert.Equal(t, "metadata", sections[0].name)
+	assert.Equal(t, "overhill", sections[0].get("name"))
+
+	dover := sections[1]
+	assert.Equal(t, []string{"version_format", "versioned_files", "pre_releases"}, dover.keys)
+	assert.Equal(t, "000.r0", dover.get("version_format"))
+	assert.Equal(t, []string{"overhill.py", "README.md:1-3"}, dover.getList("versioned_files"))
+	assert.Equal(t, []string{"alpha", "beta"}, dover.getList("pre_releases"))
+	assert.Equal(t, []string{}, dover.getList("exclude"))
+}
+
+func TestParseInvalidINI(t *testing.T) {
+	_, err := parseINI("name = overhill\n")
+	assert.EqualError(t, err, "line 1: expected a [section] or key = value")
+
+	_, err = parseINI("[dover]\nversioned_files\n")
+	assert.EqualError(t, err, "line 2: expected a [section] or key = value")
+}
+
+func TestBumpversionSearch(t *testing.T) {
+	assert.Equal(t, `(?P<version>0\.3\.0)`, bumpversionSearch("{current_version}", "0.3.0"))
+	assert.Equal(t, `version=\("(?P<version>0\.3\.0-rc\.1)"\)`, bumpversionSearch(`version=("{current_version}")`, "0.3.0-rc.1"))
+}
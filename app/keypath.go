@@ -0,0 +1,254 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+)
+
+// KEY_PATH_SEPARATOR separates a structured file from the key path of its
+// version, e.g. `package.json#version` or `pyproject.toml#project.version`.
+const KEY_PATH_SEPARATOR = "#"
+
+// keyPathFinder locates the value at a key path in a structured file. It
+// returns the zero-based line of the value and the byte offsets of the value
+// within that line, not counting any quotes.
+type keyPathFinder func(content []byte, lines []string, keyPath []string) (int, int, int, error)
+
+var KEY_PATH_FINDERS = map[string]keyPathFinder{
+	".json": findJSONKeyPath,
+	".toml": findTOMLKeyPath,
+	".yaml": findYAMLKeyPath,
+	".yml":  findYAMLKeyPath,
+}
+
+func splitFileAndKeyPath(filePath string) (string, string) {
+	index := strings.LastIndex(filePath, KEY_PATH_SEPARATOR)
+	if index == -1 {
+		return filePath, ""
+	}
+	return filePath[:index], filePath[index+1:]
+}
+
+func newKeyPathFinder(filePath string) (keyPathFinder, error) {
+	finder, found := KEY_PATH_FINDERS[strings.ToLower(filepath.Ext(filePath))]
+	if !found {
+		return nil, fmt.Errorf("%s: key paths are only supported for json, toml and yaml files", filePath)
+	}
+	return finder, nil
+}
+
+// searchKeyPath reads the version at keyPath in a structured file. The
+// version is found by its position in the file, so that it can be replaced
+// without touching anything else in the file.
+func searchKeyPath(filePath string, keyPath string, content []byte, scheme versionScheme) (*VersionMatch, error) {
+	finder, err := newKeyPathFinder(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	file := parseTextFile(content)
+	content = bytes.TrimPrefix(content, []byte(UTF8_BOM))
+	line, start, end, err := finder(content, file.lines, strings.Split(keyPath, "."))
+	if err != nil {
+		return nil, &ConfigError{Err: fmt.Errorf("%s#%s: %s", filePath, keyPath, err)}
+	}
+
+	text := file.lines[line][start:end]
+	v, length, err := scheme.parse(text)
+	if err != nil || length != len(text) {
+		return nil, &ParseError{Kind: "version", Text: text, Reason: fmt.Sprintf("at %s#%s", filePath, keyPath)}
+	}
+	return newVersionMatch(filePath, line+1, start, text, v), nil
+}
+
+// offsetToLine turns a byte offset in content into a line and the offset
+// within that line.
+func offsetToLine(content []byte, offset int) (int, int) {
+	line := bytes.Count(content[:offset], []byte("\n"))
+	lineStart := bytes.LastIndexByte(content[:offset], '\n') + 1
+	return line, offset - lineStart
+}
+
+func findJSONKeyPath(content []byte, lines []string, keyPath []string) (int, int, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	err := seekJSONKeyPath(decoder, keyPath)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	value, ok := token.(string)
+	if !ok {
+		return 0, 0, 0, errors.New("the value is not a string")
+	}
+
+	// the decoder has just read the closing quote
+	end := int(decoder.InputOffset()) - 1
+	start := end - len(value)
+	if start < 1 || string(content[start:end]) != value || content[start-1] != '"' {
+		return 0, 0, 0, errors.New("the value contains escaped characters")
+	}
+	line, column := offsetToLine(content, start)
+	return line, column, column + len(value), nil
+}
+
+// seekJSONKeyPath reads tokens up to the value at keyPath, so that the next
+// token is the value.
+func seekJSONKeyPath(decoder *json.Decoder, keyPath []string) error {
+	if len(keyPath) == 0 {
+		return nil
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			if key == keyPath[0] {
+				return seekJSONKeyPath(decoder, keyPath[1:])
+			}
+			if err := skipJSONValue(decoder); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		index, err := strconv.Atoi(keyPath[0])
+		if err != nil {
+			return fmt.Errorf("`%s` is not an array index", keyPath[0])
+		}
+		for position := 0; decoder.More(); position++ {
+			if position == index {
+				return seekJSONKeyPath(decoder, keyPath[1:])
+			}
+			if err := skipJSONValue(decoder); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("no such key: %s", keyPath[0])
+}
+
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func findTOMLKeyPath(content []byte, lines []string, keyPath []string) (int, int, int, error) {
+	tree, err := toml.LoadBytes(content)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	value := tree.GetPath(keyPath)
+	if value == nil {
+		return 0, 0, 0, fmt.Errorf("no such key: %s", strings.Join(keyPath, "."))
+	}
+	text, ok := value.(string)
+	if !ok {
+		return 0, 0, 0, errors.New("the value is not a string")
+	}
+
+	// keys inside an inline table have no position, so start the search
+	// from the nearest key that has one
+	position := toml.Position{}
+	for index := len(keyPath); index > 0 && position.Invalid(); index-- {
+		position = tree.GetPositionPath(keyPath[:index])
+	}
+	key := regexp.QuoteMeta(keyPath[len(keyPath)-1])
+	rx := regexp.MustCompile(`(?:^|[\s{,.])["']?` + key + `["']?\s*=\s*(["'])`)
+	for line := position.Line - 1; line >= 0 && line < len(lines); line++ {
+		match := rx.FindStringSubmatchIndex(lines[line])
+		if match == nil {
+			continue
+		}
+		start := match[1]
+		end := start + len(text)
+		if end > len(lines[line]) || lines[line][start:end] != text {
+			return 0, 0, 0, errors.New("the value contains escaped characters")
+		}
+		return line, start, end, nil
+	}
+	return 0, 0, 0, errors.New("the value could not be located in the file")
+}
+
+func findYAMLKeyPath(content []byte, lines []string, keyPath []string) (int, int, int, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if len(document.Content) == 0 {
+		return 0, 0, 0, fmt.Errorf("no such key: %s", strings.Join(keyPath, "."))
+	}
+
+	node := document.Content[0]
+	for _, key := range keyPath {
+		node = yamlChild(node, key)
+		if node == nil {
+			return 0, 0, 0, fmt.Errorf("no such key: %s", key)
+		}
+	}
+	if node.Kind != yaml.ScalarNode {
+		return 0, 0, 0, errors.New("the value is not a string")
+	}
+
+	line, start := node.Line-1, node.Column-1
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		start++
+	}
+	end := start + len(node.Value)
+	if line >= len(lines) || end > len(lines[line]) || lines[line][start:end] != node.Value {
+		return 0, 0, 0, errors.New("the value contains escaped characters")
+	}
+	return line, start, end, nil
+}
+
+func yamlChild(node *yaml.Node, key string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			if node.Content[index].Value == key {
+				return node.Content[index+1]
+			}
+		}
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(key)
+		if err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const PACKAGE_JSON = `{
+  "name": "project",
+  "dependencies": {
+    "left-pad": {"version": "9.9.9"}
+  },
+  "version":"0.3.0",
+  "files": [{"version": "1.0.0"}]
+}
+`
+
+const PYPROJECT_TOML = `[tool.poetry]
+version = "9.9.9"
+
+[project]
+name = "project"
+# the version
+version = '0.3.0'  # keep in sync
+
+[tool.other]
+inline = {name = "x", version = "1.2.0"}
+`
+
+const CHART_YAML = `apiVersion: v2
+name: chart
+# the chart version
+version: 1.0.0
+appVersion: "0.3.0"
+images:
+  - name: app
+    tag: 0.3.1 # the image
+`
+
+func TestSplitFileAndKeyPath(t *testing.T) {
+	filePath, keyPath := splitFileAndKeyPath("pyproject.toml#project.version")
+	assert.Equal(t, "pyproject.toml", filePath)
+	assert.Equal(t, "project.version", keyPath)
+
+	filePath, keyPath = splitFileAndKeyPath("main.go:3")
+	assert.Equal(t, "main.go:3", filePath)
+	assert.Equal(t, "", keyPath)
+}
+
+func TestSearchKeyPath(t *testing.T) {
+	var tests = []struct {
+		file, content, keyPath string
+		line, start            int
+		expected               string
+	}{
+		{"package.json", PACKAGE_JSON, "version", 6, 13, "0.3.0"},
+		{"package.json", PACKAGE_JSON, "dependencies.left-pad.version", 4, 29, "9.9.9"},
+		{"package.json", PACKAGE_JSON, "files.0.version", 7, 25, "1.0.0"},
+		{"pyproject.toml", PYPROJECT_TOML, "project.version", 7, 11, "0.3.0"},
+		{"pyproject.toml", PYPROJECT_TOML, "tool.poetry.version", 2, 11, "9.9.9"},
+		{"pyproject.toml", PYPROJECT_TOML, "tool.other.inline.version", 10, 33, "1.2.0"},
+		{"Chart.yaml", CHART_YAML, "version", 4, 9, "1.0.0"},
+		{"Chart.yaml", CHART_YAML, "appVersion", 5, 13, "0.3.0"},
+		{"Chart.yml", CHART_YAML, "images.0.tag", 8, 9, "0.3.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file+"#"+tt.keyPath, func(t *testing.T) {
+			match, err := searchKeyPath(tt.file, tt.keyPath, []byte(tt.content), semverScheme{})
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Equal(t, tt.line, match.line)
+			assert.Equal(t, tt.start, match.start)
+			assert.Equal(t, tt.expected, match.text)
+			assert.Equal(t, tt.expected, match.version.toString())
+		})
+	}
+}
+
+func TestSearchKeyPathErrors(t *testing.T) {
+	var tests = []struct {
+		file, content, keyPath, expected string
+	}{
+		{"package.json", PACKAGE_JSON, "versoin", "package.json#versoin: no such key: versoin"},
+		{"package.json", PACKAGE_JSON, "dependencies", "package.json#dependencies: the value is not a string"},
+		{"pyproject.toml", PYPROJECT_TOML, "project.versoin", "pyproject.toml#project.versoin: no such key: project.versoin"},
+		{"Chart.yaml", CHART_YAML, "images.1.tag", "Chart.yaml#images.1.tag: no such key: 1"},
+		{"Chart.yaml", CHART_YAML, "name", "invalid version: chart at Chart.yaml#name"},
+		{"VERSION", "0.3.0", "version", "VERSION: key paths are only supported for json, toml and yaml files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file+"#"+tt.keyPath, func(t *testing.T) {
+			_, err := searchKeyPath(tt.file, tt.keyPath, []byte(tt.content), semverScheme{})
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
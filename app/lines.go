@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LINE_NOTATION_SEPARATOR separates a versioned file from the lines to
+// search, e.g. `README.md:1-20,42,-3`.
+//
+// Lines are numbered from 1. An item is a line, a negative line counted back
+// from the last line (-1 is the last line), or a range of either: `10-20`,
+// `-5--1`, and `20-` for line 20 to the end of the file.
+const LINE_NOTATION_SEPARATOR = ":"
+
+// lineRange is an item of a line notation. A single line has the same
+// start and end, an open range an end of 0.
+type lineRange struct {
+	start int
+	end   int
+}
+
+// splitFileAndLineNotation splits off the line notation after the last
+// colon, leaving the drive of a windows path (`C:\proj\main.go`) alone.
+func splitFileAndLineNotation(filePath string) (string, string) {
+	index := strings.LastIndex(filePath, LINE_NOTATION_SEPARATOR)
+	if index == -1 || isWindowsDrive(filePath, index) {
+		return filePath, ""
+	}
+	return filePath[:index], filePath[index+1:]
+}
+
+func isWindowsDrive(filePath string, colon int) bool {
+	if colon != 1 {
+		return false
+	}
+	drive := filePath[0]
+	return ('a' <= drive && drive <= 'z') || ('A' <= drive && drive <= 'Z')
+}
+
+func parseLineNotation(notation string) ([]lineRange, error) {
+	ranges := []lineRange{}
+	for _, item := range strings.Split(notation, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return nil, fmt.Errorf("empty line in `%s`", notation)
+		}
+
+		start, rest, err := parseLineBound(item)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if rest != "" {
+			if rest[0] != '-' {
+				return nil, fmt.Errorf("`%s` is not a line or range of lines", item)
+			}
+			end = 0
+			if rest != "-" {
+				var extra string
+				end, extra, err = parseLineBound(rest[1:])
+				if err != nil || extra != "" {
+					return nil, fmt.Errorf("`%s` is not a line or range of lines", item)
+				}
+			}
+		}
+		if end != 0 && (start > 0) == (end > 0) && start > end {
+			return nil, fmt.Errorf("range `%s` ends before it starts", item)
+		}
+		ranges = append(ranges, lineRange{start: start, end: end})
+	}
+	return ranges, nil
+}
+
+// parseLineBound reads a line number, with an optional minus sign, from the
+// start of text and returns the text that follows it.
+func parseLineBound(text string) (int, string, error) {
+	length := 0
+	if strings.HasPrefix(text, "-") {
+		length++
+	}
+	for length < len(text) && '0' <= text[length] && text[length] <= '9' {
+		length++
+	}
+	line, err := strconv.Atoi(text[:length])
+	if err != nil {
+		return 0, "", fmt.Errorf("`%s` is not a line or range of lines", text)
+	}
+	if line == 0 {
+		return 0, "", fmt.Errorf("line numbers start at 1, got `%s`", text[:length])
+	}
+	return line, text[length:], nil
+}
+
+// resolveLineRanges turns the ranges into line numbers of a file with count
+// lines. Lines past the end of the file are dropped.
+func resolveLineRanges(ranges []lineRange, count int) []int {
+	resolve := func(line int) int {
+		if line < 0 {
+			return count + line + 1
+		}
+		return line
+	}
+
+	lines := []int{}
+	for _, r := range ranges {
+		start, end := resolve(r.start), count
+		if r.end != 0 {
+			end = resolve(r.end)
+		}
+		if start < 1 {
+			start = 1
+		}
+		for line := start; line <= end && line <= count; line++ {
+			if IndexOf(&lines, line) == -1 {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// lineCount is the number of lines a file has, which does not count the
+// empty "line" after a trailing newline.
+func lineCount(lines []string) int {
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		return len(lines) - 1
+	}
+	return len(lines)
+}
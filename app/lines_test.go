@@ -0,0 +1,64 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitFileAndLineNotation(t *testing.T) {
+	var tests = []struct {
+		path, file, notation string
+	}{
+		{"main.go", "main.go", ""},
+		{"main.go:3", "main.go", "3"},
+		{"README.md:1-20,42,-3", "README.md", "1-20,42,-3"},
+		{`C:\proj\main.go`, `C:\proj\main.go`, ""},
+		{`C:\proj\main.go:3`, `C:\proj\main.go`, "3"},
+		{"c:/proj/main.go:-1", "c:/proj/main.go", "-1"},
+	}
+	for _, tt := range tests {
+		file, notation := splitFileAndLineNotation(tt.path)
+		assert.Equal(t, tt.file, file, tt.path)
+		assert.Equal(t, tt.notation, notation, tt.path)
+	}
+}
+
+func TestParseLineNotation(t *testing.T) {
+	ranges, err := parseLineNotation("1-3, 42,-3,-5--2,20-")
+	assert.Nil(t, err)
+	assert.Equal(t, []lineRange{{1, 3}, {42, 42}, {-3, -3}, {-5, -2}, {20, 0}}, ranges)
+}
+
+func TestInvalidLineNotation(t *testing.T) {
+	var tests = []struct {
+		notation, expected string
+	}{
+		{"", "empty line in ``"},
+		{"1,,2", "empty line in `1,,2`"},
+		{"abc", "`abc` is not a line or range of lines"},
+		{"3x", "`3x` is not a line or range of lines"},
+		{"1-2-3", "`1-2-3` is not a line or range of lines"},
+		{"0", "line numbers start at 1, got `0`"},
+		{"20-10", "range `20-10` ends before it starts"},
+		{"-1--3", "range `-1--3` ends before it starts"},
+	}
+	for _, tt := range tests {
+		_, err := parseLineNotation(tt.notation)
+		assert.EqualError(t, err, tt.expected, tt.notation)
+	}
+
+	_, _, err := parseVersionedFileConfig("main.go:3,x")
+	assert.EqualError(t, err, "invalid line notation: main.go:3,x (`x` is not a line or range of lines)")
+}
+
+func TestResolveLineRanges(t *testing.T) {
+	ranges, _ := parseLineNotation("2-4,-1,9-,-2,20")
+	assert.Equal(t, []int{2, 3, 4, 10, 9}, resolveLineRanges(ranges, 10))
+
+	ranges, _ = parseLineNotation("-20-2")
+	assert.Equal(t, []int{1, 2}, resolveLineRanges(ranges, 10))
+
+	assert.Equal(t, 2, lineCount([]string{"a", "b", ""}))
+	assert.Equal(t, 2, lineCount([]string{"a", "b"}))
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Markers are comments in a versioned file that tell dover which lines to
+// track, whatever the comment syntax of the file:
+//
+//	VERSION = "0.3.0"  # dover:version
+//	// dover:ignore
+//	<!-- dover:ignore-start --> ... <!-- dover:ignore-end -->
+//
+// A marker on a line of its own applies to the line that follows it.
+const (
+	MARKER_VERSION      = "version"
+	MARKER_IGNORE       = "ignore"
+	MARKER_IGNORE_START = "ignore-start"
+	MARKER_IGNORE_END   = "ignore-end"
+)
+
+var MARKER_RX = regexp.MustCompile(`(?:#|//|<!--)\s*dover:(ignore-start|ignore-end|ignore|version)\b`)
+
+// lineMarkers are the lines of a file that its markers force to be tracked
+// or to be ignored.
+type lineMarkers struct {
+	tracked map[int]bool
+	ignored map[int]bool
+}
+
+func readLineMarkers(lines []string) lineMarkers {
+	markers := lineMarkers{tracked: map[int]bool{}, ignored: map[int]bool{}}
+	ignoring := false
+	for index, line := range lines {
+		match := MARKER_RX.FindStringSubmatchIndex(line)
+		if match == nil {
+			if ignoring {
+				markers.ignored[index] = true
+			}
+			continue
+		}
+
+		target := index
+		if strings.TrimSpace(line[:match[0]]) == "" {
+			target = index + 1
+		}
+		switch line[match[2]:match[3]] {
+		case MARKER_IGNORE_START:
+			ignoring = true
+		case MARKER_IGNORE_END:
+			ignoring = false
+			markers.ignored[index] = true
+		case MARKER_IGNORE:
+			markers.ignored[target] = true
+		case MARKER_VERSION:
+			markers.tracked[target] = true
+		}
+		if ignoring {
+			markers.ignored[index] = true
+		}
+	}
+	return markers
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const MARKED_README = `# dover
+
+Install with:
+
+    pip install dover==0.3.0  <!-- dover:version -->
+
+<!-- dover:version -->
+Current release: 0.3.0
+
+Set the version with:
+
+    VERSION = "9.9.9"  # dover:ignore
+
+// dover:ignore
+version: 8.8.8
+
+<!-- dover:ignore-start -->
+## History
+version 0.2.0
+version 0.1.0
+<!-- dover:ignore-end -->
+version = "0.3.0"
+`
+
+func TestReadLineMarkers(t *testing.T) {
+	markers := readLineMarkers(strings.Split(MARKED_README, "\n"))
+	assert.Equal(t, map[int]bool{4: true, 7: true}, markers.tracked)
+	assert.Equal(t, map[int]bool{11: true, 14: true, 16: true, 17: true, 18: true, 19: true, 20: true}, markers.ignored)
+}
+
+func TestSearchWithMarkers(t *testing.T) {
+	lines := strings.Split(MARKED_README, "\n")
+	finder := NewVersionFinder(semverScheme{})
+
+	matches, err := searchForVersionString(versionedFile{path: "README.md"}, []int{}, lines, finder)
+	assert.Nil(t, err)
+	found := []int{}
+	for _, match := range matches {
+		assert.Equal(t, "0.3.0", match.text)
+		found = append(found, match.line)
+	}
+	assert.Equal(t, []int{5, 8, 22}, found)
+
+	// marked lines are tracked on top of the line notation
+	matches, err = searchForVersionString(versionedFile{path: "README.md:22"}, []int{22}, lines, finder)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(matches))
+
+	// an ignored line is skipped even when it is asked for
+	matches, err = searchForVersionString(versionedFile{path: "README.md:12"}, []int{12}, lines, finder)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(matches))
+}
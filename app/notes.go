@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// The changelogs looked for when the config has no changelog.
+var DEFAULT_CHANGELOGS = []string{"CHANGELOG.md", "HISTORY.md"}
+
+// ReleaseNotes is the section of the changelog File for a version: its
+// Heading and the markdown under it. Version is nil for the Unreleased
+// section.
+type ReleaseNotes struct {
+	File    string
+	Heading string
+	Version *Version
+	Body    string
+}
+
+// changelogFile is the config's changelog, or else the first of the
+// default changelogs the project has.
+func (p *Project) changelogFile() (string, error) {
+	if p.config.changelog != "" {
+		return p.config.changelog, nil
+	}
+	for _, file := range DEFAULT_CHANGELOGS {
+		if fileExists(filepath.Join(p.dir, file)) {
+			return file, nil
+		}
+	}
+	return "", &ConfigError{Err: fmt.Errorf("no changelog found, add one to the dover config: changelog = \"HISTORY.md\"")}
+}
+
+// notesVersion reads the version the notes are asked for, the current
+// version when it is empty. A `v` prefix is left out, as in a `v0.3.0` tag.
+// The Unreleased section is asked for by name, with a nil version.
+func (p *Project) notesVersion(version string) (*Version, error) {
+	if version == "" {
+		return p.Current()
+	}
+	if strings.EqualFold(version, CHANGELOG_UNRELEASED) {
+		return nil, nil
+	}
+
+	text := strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	v, length, err := p.config.scheme.parse(text)
+	if err != nil || length != len(text) {
+		return nil, &ParseError{Kind: "version", Text: version}
+	}
+	return v, nil
+}
+
+// Notes finds the section of the changelog for the version, the current
+// version when it is empty or the Unreleased section for `unreleased`. A
+// heading is for the version when the first version in it is the same, so
+// `## [0.3.0] - 2025-07-04` and `##### v0.3.0 (July 2025)` are both found
+// for 0.3.0, and so is `## 0.3.0a0` for 0.3.0-alpha.0.
+func (p *Project) Notes(version string) (*ReleaseNotes, error) {
+	target, err := p.notesVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	file, err := p.changelogFile()
+	if err != nil {
+		return nil, err
+	}
+	changelog, err := readChangelog(p.dir, file)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := changelog.lines
+	finder := newMarkerFinder(p.config.scheme)
+	for index, line := range lines {
+		level, text := markdownHeading(line)
+		if level == 0 {
+			continue
+		}
+
+		if target == nil && !isUnreleasedHeading(text) {
+			continue
+		}
+		if target != nil {
+			found := finder.find(text)
+			if found == nil || !found.version.equals(target) {
+				continue
+			}
+		}
+
+		body := lines[index+1 : sectionEnd(lines, index)]
+		return &ReleaseNotes{
+			File:    file,
+			Heading: text,
+			Version: target,
+			Body:    strings.Trim(strings.Join(body, "\n"), "\n"),
+		}, nil
+	}
+
+	if target == nil {
+		return nil, fmt.Errorf("%s has no Unreleased section", file)
+	}
+	return nil, fmt.Errorf("%s has no section for %s", file, target.format(p.config.format))
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+)
+
+const KEEP_A_CHANGELOG = `# Changelog
+
+## [Unreleased]
+- added notes
+
+## [0.3.0] - 2025-07-04
+### Added
+- added --echo
+
+### Fixed
+- read CRLF files
+
+## [0.2.0] - 2025-03-01
+- added components
+`
+
+const DOVER_CHANGELOG = `### RELEASES
+
+##### v0.3.0 (July 2025)
+
+- added --echo
+
+##### v0.3.0a0 (June 2025)
+
+- first pre-release
+`
+
+func (suite *ProjectTestSuite) TestNotes() {
+	tests := []struct {
+		name      string
+		changelog string
+		version   string
+		heading   string
+		body      string
+	}{
+		{"current", KEEP_A_CHANGELOG, "", "[0.3.0] - 2025-07-04", "### Added\n- added --echo\n\n### Fixed\n- read CRLF files"},
+		{"given", KEEP_A_CHANGELOG, "0.2.0", "[0.2.0] - 2025-03-01", "- added components"},
+		{"v prefix", KEEP_A_CHANGELOG, "v0.2.0", "[0.2.0] - 2025-03-01", "- added components"},
+		{"unreleased", KEEP_A_CHANGELOG, "unreleased", "[Unreleased]", "- added notes"},
+		{"dover headings", DOVER_CHANGELOG, "", "v0.3.0 (July 2025)", "- added --echo"},
+		{"pre-release", DOVER_CHANGELOG, "0.3.0-alpha.0", "v0.3.0a0 (June 2025)", "- first pre-release"},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.writeChangelogProject(tt.changelog)
+			project, err := LoadProject(suite.tempDir)
+			suite.Nil(err)
+
+			notes, err := project.Notes(tt.version)
+			suite.Nil(err)
+			suite.Equal("HISTORY.md", notes.File)
+			suite.Equal(tt.heading, notes.Heading)
+			suite.Equal(tt.body, notes.Body)
+		})
+	}
+}
+
+func (suite *ProjectTestSuite) TestNotesErrors() {
+	suite.writeChangelogProject(DOVER_CHANGELOG)
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	_, err = project.Notes("0.4.0")
+	suite.Equal("HISTORY.md has no section for 0.4.0", fmt.Sprint(err))
+	_, err = project.Notes("unreleased")
+	suite.Equal("HISTORY.md has no Unreleased section", fmt.Sprint(err))
+	_, err = project.Notes("0.x")
+	suite.IsType(&ParseError{}, err)
+}
+
+func (suite *ProjectTestSuite) TestNotesDefaultChangelog() {
+	suite.writeFile(".dover", "[dover]\nversion_format = \"000\"\nversioned_files = [\"coding.go\"]\n")
+	suite.writeFile("coding.go", "package coding\n\nconst VERSION = \"0.2.0\"\n")
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	_, err = project.Notes("")
+	suite.IsType(&ConfigError{}, err)
+
+	suite.writeFile("HISTORY.md", KEEP_A_CHANGELOG)
+	notes, err := project.Notes("")
+	suite.Nil(err)
+	suite.Equal("HISTORY.md", notes.File)
+	suite.Equal("- added components", notes.Body)
+
+	suite.writeFile("CHANGELOG.md", "# Changelog\n\n## 0.2.0\n- from CHANGELOG.md\n")
+	notes, err = project.Notes("")
+	suite.Nil(err)
+	suite.Equal("CHANGELOG.md", notes.File)
+	suite.Equal("- from CHANGELOG.md", notes.Body)
+}
@@ -0,0 +1,223 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OUTPUT_TEXT = "text"
+	OUTPUT_JSON = "json"
+	OUTPUT_YAML = "yaml"
+)
+
+var OUTPUT_FORMATS = []string{OUTPUT_TEXT, OUTPUT_JSON, OUTPUT_YAML}
+
+// versionDocument is a version along with its parsed components.
+type versionDocument struct {
+	Version     string   `json:"version" yaml:"version"`
+	Scheme      string   `json:"scheme" yaml:"scheme"`
+	Epoch       string   `json:"epoch,omitempty" yaml:"epoch,omitempty"`
+	Major       string   `json:"major" yaml:"major"`
+	Minor       string   `json:"minor" yaml:"minor"`
+	Patch       string   `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Segments    []string `json:"segments,omitempty" yaml:"segments,omitempty"`
+	PreRelease  string   `json:"pre_release,omitempty" yaml:"pre_release,omitempty"`
+	Build       string   `json:"build,omitempty" yaml:"build,omitempty"`
+	Identifiers []string `json:"identifiers,omitempty" yaml:"identifiers,omitempty"`
+	Post        string   `json:"post,omitempty" yaml:"post,omitempty"`
+	Dev         string   `json:"dev,omitempty" yaml:"dev,omitempty"`
+	Metadata    []string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// matchDocument is a version found in a versioned file. Text is the version
+// as it is written in the file, at the byte offsets Start to End of the line.
+type matchDocument struct {
+	File    string          `json:"file" yaml:"file"`
+	Line    int             `json:"line" yaml:"line"`
+	Column  int             `json:"column" yaml:"column"`
+	Start   int             `json:"start" yaml:"start"`
+	End     int             `json:"end" yaml:"end"`
+	Text    string          `json:"text" yaml:"text"`
+	Version versionDocument `json:"version" yaml:"version"`
+}
+
+type changeDocument struct {
+	File   string `json:"file" yaml:"file"`
+	Line   int    `json:"line" yaml:"line"`
+	Column int    `json:"column" yaml:"column"`
+	Old    string `json:"old" yaml:"old"`
+	New    string `json:"new" yaml:"new"`
+}
+
+// changelogDocument is the section a plan adds to the changelog.
+type changelogDocument struct {
+	File    string   `json:"file" yaml:"file"`
+	Heading string   `json:"heading" yaml:"heading"`
+	Entries []string `json:"entries" yaml:"entries"`
+	Warning string   `json:"warning,omitempty" yaml:"warning,omitempty"`
+}
+
+// notesDocument is the changelog section of a version, Body in markdown.
+type notesDocument struct {
+	File    string `json:"file" yaml:"file"`
+	Heading string `json:"heading" yaml:"heading"`
+	Body    string `json:"body" yaml:"body"`
+}
+
+type errorDocument struct {
+	Type     string          `json:"type" yaml:"type"`
+	Message  string          `json:"message" yaml:"message"`
+	ExitCode int             `json:"exit_code" yaml:"exit_code"`
+	Matches  []matchDocument `json:"matches,omitempty" yaml:"matches,omitempty"`
+}
+
+// componentDocument is a component of the project and its version. When the
+// version can not be read, Error says why.
+type componentDocument struct {
+	Name       string           `json:"name" yaml:"name"`
+	Version    *versionDocument `json:"version,omitempty" yaml:"version,omitempty"`
+	Consistent bool             `json:"consistent" yaml:"consistent"`
+	Error      *errorDocument   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// outputDocument is what the show, plan, echo, apply, sync, notes and
+// components commands write with --output=json or --output=yaml.
+type outputDocument struct {
+	Command    string              `json:"command" yaml:"command"`
+	Components []componentDocument `json:"components,omitempty" yaml:"components,omitempty"`
+	Version    *versionDocument    `json:"version,omitempty" yaml:"version,omitempty"`
+	Next       *versionDocument    `json:"next,omitempty" yaml:"next,omitempty"`
+	Matches    []matchDocument     `json:"matches,omitempty" yaml:"matches,omitempty"`
+	Changes    []changeDocument    `json:"changes,omitempty" yaml:"changes,omitempty"`
+	Changelog  *changelogDocument  `json:"changelog,omitempty" yaml:"changelog,omitempty"`
+	Notes      *notesDocument      `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Applied    *bool               `json:"applied,omitempty" yaml:"applied,omitempty"`
+	Committed  bool                `json:"committed,omitempty" yaml:"committed,omitempty"`
+	Tag        string              `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Error      *errorDocument      `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func newVersionDocument(v *Version, format string) *versionDocument {
+	doc := versionDocument{
+		Version:     v.format(format),
+		Scheme:      v.getScheme().name(),
+		Epoch:       v.epoch,
+		Major:       v.major,
+		Minor:       v.minor,
+		Patch:       v.patch,
+		Segments:    v.segments,
+		PreRelease:  v.release,
+		Identifiers: v.identifiers,
+		Post:        v.post,
+		Dev:         v.dev,
+		Metadata:    v.metadata,
+	}
+	if v.release != "" {
+		// the build number belongs to the pre-release
+		doc.Build = v.build
+	}
+	return &doc
+}
+
+func newMatchDocuments(matches []*VersionMatch, format string) []matchDocument {
+	documents := []matchDocument{}
+	for _, match := range matches {
+		documents = append(documents, matchDocument{
+			File:    match.file,
+			Line:    match.line,
+			Column:  match.Column(),
+			Start:   match.start,
+			End:     match.end,
+			Text:    match.text,
+			Version: *newVersionDocument(match.version, format),
+		})
+	}
+	return documents
+}
+
+func newPlanDocument(command string, project *Project, plan *Plan, applied bool) outputDocument {
+	doc := outputDocument{
+		Command: command,
+		Next:    newVersionDocument(plan.Next, plan.Bump.Format),
+		Matches: newMatchDocuments(project.Matches(), plan.Bump.Format),
+		Applied: &applied,
+	}
+	if plan.Current != nil {
+		// a sync plan has no current version
+		doc.Version = newVersionDocument(plan.Current, plan.Bump.Format)
+	}
+	for _, change := range plan.Changes {
+		doc.Changes = append(doc.Changes, changeDocument{
+			File:   change.Match.file,
+			Line:   change.Match.line,
+			Column: change.Match.Column(),
+			Old:    change.Old,
+			New:    change.New,
+		})
+	}
+	if plan.Changelog != nil {
+		doc.Changelog = &changelogDocument{
+			File:    plan.Changelog.File,
+			Heading: plan.Changelog.Heading,
+			Entries: plan.Changelog.Entries,
+		}
+		if plan.Changelog.err != nil {
+			doc.Changelog.Warning = plan.Changelog.err.Error()
+		}
+	}
+	return doc
+}
+
+func newErrorDocument(command string, err error, format string) outputDocument {
+	doc := errorDocument{
+		Type:     errorType(err),
+		Message:  err.Error(),
+		ExitCode: exitCode(err),
+	}
+	var inconsistentError *InconsistentVersionError
+	if errors.As(err, &inconsistentError) {
+		doc.Matches = newMatchDocuments(inconsistentError.Matches, format)
+	}
+	return outputDocument{Command: command, Error: &doc}
+}
+
+// errorType names the kind of error for the error document.
+func errorType(err error) string {
+	switch exitCode(err) {
+	case EXIT_USAGE:
+		return "usage"
+	case EXIT_CONFIG_ERROR:
+		return "config"
+	case EXIT_PARSE_ERROR:
+		return "parse"
+	case EXIT_INCONSISTENT_VERSIONS:
+		return "inconsistent_versions"
+	case EXIT_INVALID_FORMAT:
+		return "invalid_format"
+	case EXIT_RELEASE_ORDER:
+		return "release_order"
+	case EXIT_GIT_ERROR:
+		return "git"
+	}
+	return "error"
+}
+
+func writeDocument(w io.Writer, output string, doc outputDocument) error {
+	switch output {
+	case OUTPUT_JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	case OUTPUT_YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+		return encoder.Encode(doc)
+	}
+	return fmt.Errorf("unknown output format: %s", output)
+}
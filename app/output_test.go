@@ -0,0 +1,85 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestVersionDocument(t *testing.T) {
+	v, err := parseVersion("1.4.0-beta.2.hotfix+sha.5114f85")
+	assert.Nil(t, err)
+
+	doc := newVersionDocument(v, CANONICAL_FORMAT)
+	assert.Equal(t, "1.4.0-beta.2.hotfix+sha.5114f85", doc.Version)
+	assert.Equal(t, "semver", doc.Scheme)
+	assert.Equal(t, "4", doc.Minor)
+	assert.Equal(t, "beta", doc.PreRelease)
+	assert.Equal(t, "2", doc.Build)
+	assert.Equal(t, []string{"hotfix"}, doc.Identifiers)
+	assert.Equal(t, []string{"sha", "5114f85"}, doc.Metadata)
+
+	v, err = parseVersion("1.4.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "", newVersionDocument(v, CANONICAL_FORMAT).Build)
+}
+
+func TestWriteJSONDocument(t *testing.T) {
+	v, _ := parseVersion("0.3.0-rc.1")
+	match := newVersionMatch("main.go", 4, 17, "0.3.0-rc1", v)
+	next, _ := v.bump("", "release")
+	applied := false
+	doc := outputDocument{
+		Command: "plan",
+		Version: newVersionDocument(v, CANONICAL_FORMAT),
+		Next:    newVersionDocument(&next, CANONICAL_FORMAT),
+		Matches: newMatchDocuments([]*VersionMatch{match}, CANONICAL_FORMAT),
+		Changes: []changeDocument{{File: "main.go", Line: 4, Old: "0.3.0-rc.1", New: "0.3.0"}},
+		Applied: &applied,
+	}
+
+	var b bytes.Buffer
+	err := writeDocument(&b, OUTPUT_JSON, doc)
+	assert.Nil(t, err)
+
+	var payload map[string]any
+	err = json.Unmarshal(b.Bytes(), &payload)
+	assert.Nil(t, err)
+	assert.Equal(t, "plan", payload["command"])
+	assert.Equal(t, "0.3.0", payload["next"].(map[string]any)["version"])
+	assert.Equal(t, "0.3.0-rc1", payload["matches"].([]any)[0].(map[string]any)["text"])
+	assert.Equal(t, "0.3.0", payload["changes"].([]any)[0].(map[string]any)["new"])
+	assert.Equal(t, false, payload["applied"])
+	assert.NotContains(t, payload, "error")
+}
+
+func TestWriteYAMLErrorDocument(t *testing.T) {
+	v1, _ := parseVersion("0.3.0")
+	v2, _ := parseVersion("0.2.0")
+	err := &InconsistentVersionError{Matches: []*VersionMatch{
+		newVersionMatch("main.go", 4, 17, "0.3.0", v1),
+		newVersionMatch("setup.py", 1, 15, "0.2.0", v2),
+	}}
+
+	var b bytes.Buffer
+	assert.Nil(t, writeDocument(&b, OUTPUT_YAML, newErrorDocument("show", err, CANONICAL_FORMAT)))
+
+	var doc outputDocument
+	assert.Nil(t, yaml.Unmarshal(b.Bytes(), &doc))
+	assert.Equal(t, "show", doc.Command)
+	assert.Nil(t, doc.Version)
+	assert.Equal(t, "inconsistent_versions", doc.Error.Type)
+	assert.Equal(t, EXIT_INCONSISTENT_VERSIONS, doc.Error.ExitCode)
+	assert.Equal(t, 2, len(doc.Error.Matches))
+	assert.Equal(t, "setup.py", doc.Error.Matches[1].File)
+}
+
+func TestCommandName(t *testing.T) {
+	assert.Equal(t, "show", commandName(ExecutionArgs{}))
+	assert.Equal(t, "echo", commandName(ExecutionArgs{echo: true, part: "minor"}))
+	assert.Equal(t, "plan", commandName(ExecutionArgs{part: "minor"}))
+	assert.Equal(t, "apply", commandName(ExecutionArgs{increment: true, preRelease: "beta"}))
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package app
+
+import (
+	"os"
+	"syscall"
+)
+
+// copyOwner gives path the owner and group of info. Only root can give a file
+// away, so for anyone else a file that already has a different owner is left
+// as it is.
+func copyOwner(path string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	err := os.Lchown(path, int(stat.Uid), int(stat.Gid))
+	if os.IsPermission(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFileUpdatesPreservesOwner(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing a file's owner needs root")
+	}
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"main.go": "const VERSION = \"0.1.0\"\n"})
+	filePath := filepath.Join(dir, "main.go")
+	assert.Nil(t, os.Chown(filePath, 1234, 5678))
+
+	changes := testVersionChanges(t, dir, []string{"main.go"}, "0.2.0")
+	updates, err := prepareFileUpdates(dir, changes)
+	assert.Nil(t, err)
+	assert.Nil(t, writeFileUpdates(updates))
+
+	info, err := os.Stat(filePath)
+	assert.Nil(t, err)
+	stat := info.Sys().(*syscall.Stat_t)
+	assert.Equal(t, uint32(1234), stat.Uid)
+	assert.Equal(t, uint32(5678), stat.Gid)
+}
+
+func TestWriteFileUpdatesPreservesSpecialBits(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"main.go": "const VERSION = \"0.1.0\"\n"})
+	filePath := filepath.Join(dir, "main.go")
+	mode := os.FileMode(0755) | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+	assert.Nil(t, os.Chmod(filePath, mode))
+
+	changes := testVersionChanges(t, dir, []string{"main.go"}, "0.2.0")
+	updates, err := prepareFileUpdates(dir, changes)
+	assert.Nil(t, err)
+	assert.Nil(t, writeFileUpdates(updates))
+
+	info, err := os.Stat(filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, mode, info.Mode())
+}
@@ -0,0 +1,320 @@
+package app
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// PEP440_VERSION follows the regex published with PEP 440, minus the
+// single-segment release which is too loose to search for.
+const PEP440_VERSION = `(?i)v?(?:(?P<epoch>\d+)!)?(?P<segments>\d+(?:\.\d+)+)` +
+	`(?:[-_\.]?(?P<pre_l>alpha|beta|preview|pre|rc|a|b|c)[-_\.]?(?P<pre_n>\d+)?)?` +
+	`(?:-(?P<post_n1>\d+)|[-_\.]?(?P<post_l>post|rev|r)[-_\.]?(?P<post_n2>\d+)?)?` +
+	`(?:[-_\.]?(?P<dev_l>dev)[-_\.]?(?P<dev_n>\d+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_\.][a-z0-9]+)*))?`
+
+var (
+	pep440Regex     = regexp.MustCompile(`^(?:` + PEP440_VERSION + `)`)
+	PEP440_RELEASES = &releaseLadder{labels: []releaseLabel{
+		{long: "alpha", short: "a"},
+		{long: "beta", short: "b"},
+		{long: "rc", short: "rc"},
+	}}
+	// PEP440_SPELLINGS are the alternative spellings PEP 440 normalizes
+	PEP440_SPELLINGS = map[string]string{"c": "rc", "pre": "rc", "preview": "rc"}
+)
+
+// pep440Scheme implements PEP 440 for Python projects. Versions are always
+// written in their normalized form, so the version format is not used.
+//
+// A PEP 440 version maps onto Version as:
+//
+//	epoch!major.minor[.patch][.segments...][{release}{build}][.post{post}][.dev{dev}][+metadata]
+type pep440Scheme struct{}
+
+func (s pep440Scheme) name() string {
+	return PEP440_SCHEME
+}
+
+func (s pep440Scheme) pattern() string {
+	return PEP440_VERSION
+}
+
+func (s pep440Scheme) parse(text string) (*Version, int, error) {
+	match := pep440Regex.FindStringSubmatch(text)
+	if match == nil {
+		return nil, 0, &ParseError{Kind: "PEP 440 version", Text: text}
+	}
+	group := func(name string) string {
+		return strings.ToLower(match[pep440Regex.SubexpIndex(name)])
+	}
+
+	segments := strings.Split(group("segments"), ".")
+	for index, segment := range segments {
+		segments[index] = normalizeNumber(segment)
+	}
+
+	v := Version{
+		major:  segments[0],
+		minor:  segments[1],
+		scheme: s,
+	}
+	if len(segments) > 2 {
+		v.patch = segments[2]
+		v.segments = segments[3:]
+	}
+	if len(v.segments) == 0 {
+		v.segments = nil
+	}
+
+	if epoch := group("epoch"); epoch != "" && normalizeNumber(epoch) != "0" {
+		v.epoch = normalizeNumber(epoch)
+	}
+	if label := group("pre_l"); label != "" {
+		if spelling, found := PEP440_SPELLINGS[label]; found {
+			label = spelling
+		}
+		v.release = PEP440_RELEASES.longName(label)
+		v.build = normalizeNumber(defaultZeroStr(group("pre_n")))
+	}
+	if group("post_n1") != "" || group("post_l") != "" {
+		v.post = normalizeNumber(defaultZeroStr(group("post_n1") + group("post_n2")))
+	}
+	if group("dev_l") != "" {
+		v.dev = normalizeNumber(defaultZeroStr(group("dev_n")))
+	}
+	if local := group("local"); local != "" {
+		v.metadata = strings.FieldsFunc(local, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})
+	}
+
+	return &v, len(match[0]), nil
+}
+
+func (s pep440Scheme) format(v *Version, f *Formatter) string {
+	output := []string{}
+	if v.epoch != "" {
+		output = append(output, v.epoch, "!")
+	}
+	output = append(output, strings.Join(v.releaseSegments(), "."))
+	if v.release != "" {
+		output = append(output, PEP440_RELEASES.shortName(v.release), defaultZeroStr(v.build))
+	}
+	if v.post != "" {
+		output = append(output, ".post", v.post)
+	}
+	if v.dev != "" {
+		output = append(output, ".dev", v.dev)
+	}
+	if len(v.metadata) > 0 {
+		output = append(output, "+", strings.Join(v.metadata, "."))
+	}
+	return strings.Join(output, "")
+}
+
+// bump applies dover's options the way PEP 440 defines them:
+//
+//   - --major, --minor, --patch start a new final release.
+//   - --alpha, --beta, --rc start a pre-release, or bump its number. On a
+//     developmental pre-release (1.0rc1.dev4) they release it (1.0rc1).
+//   - --dev starts a developmental release (.dev0) or bumps its number.
+//   - --post starts a post release (.post1) or bumps its number. On a
+//     developmental post release it releases it.
+//   - --release drops the pre-release and developmental segments, and the
+//     post release of a pre-release (1.0a1.post2 releases 1.0).
+//   - --build bumps the right-most of dev, post or pre-release number.
+//
+// The local version is always dropped.
+func (s pep440Scheme) bump(v *Version, part string, preRelease string) (Version, error) {
+	nv := v.copy()
+	nv.metadata = nil
+
+	switch part {
+	case "major", "minor", "patch":
+		nv = nv.bumpReleaseSegment(part)
+	case "calver":
+		return Version{}, &UsageError{Err: errors.New("--calver is only supported by the calver version scheme")}
+	case "build":
+		if preRelease == "" {
+			switch {
+			case nv.dev != "":
+				nv.dev = incrementNumber(nv.dev)
+			case nv.post != "":
+				nv.post = incrementNumber(nv.post)
+			case nv.release != "":
+				nv.build = incrementNumber(nv.build)
+			}
+		}
+	}
+
+	switch preRelease {
+	case "":
+	case "pre-release":
+		nv.release = PEP440_RELEASES.nextRelease(nv.release)
+		nv.build = ""
+		if nv.release != "" {
+			nv.build = "0"
+		}
+		nv.post = ""
+		nv.dev = ""
+	case "dev":
+		if nv.dev == "" {
+			nv.dev = "0"
+		} else {
+			nv.dev = incrementNumber(nv.dev)
+		}
+	case "post":
+		if nv.post != "" && nv.dev != "" {
+			nv.dev = ""
+		} else if nv.post != "" {
+			nv.post = incrementNumber(nv.post)
+		} else {
+			nv.post = "1"
+			nv.dev = ""
+		}
+	case "release":
+		if nv.release != "" {
+			// a post release of the pre-release is not one of the final release
+			nv.post = ""
+		}
+		nv.release = ""
+		nv.build = ""
+		nv.dev = ""
+	default:
+		err := PEP440_RELEASES.validateReleaseOrder(nv.release, preRelease)
+		if err != nil {
+			return Version{}, err
+		}
+
+		release := PEP440_RELEASES.longName(preRelease)
+		if nv.release == release && nv.dev != "" {
+			nv.dev = ""
+		} else if nv.release == release {
+			nv.build = incrementNumber(nv.build)
+		} else {
+			nv.release = release
+			nv.build = "0"
+		}
+		nv.post = ""
+		nv.dev = ""
+	}
+
+	return nv, nil
+}
+
+func (v *Version) bumpReleaseSegment(part string) Version {
+	nv := v.copy()
+	index := IndexOf(&[]string{"major", "minor", "patch"}, part)
+	for i, segment := range []*string{&nv.major, &nv.minor, &nv.patch} {
+		if i == index {
+			*segment = incrementNumber(*segment)
+		} else if i > index && *segment != "" {
+			*segment = "0"
+		}
+	}
+	for i := range nv.segments {
+		nv.segments[i] = "0"
+	}
+
+	nv.release = ""
+	nv.build = ""
+	nv.post = ""
+	nv.dev = ""
+	return nv
+}
+
+func (s pep440Scheme) releases() *releaseLadder {
+	return PEP440_RELEASES
+}
+
+// compare orders versions as PEP 440 does: developmental releases come
+// before pre-releases, which come before the final release, which comes
+// before its post releases.
+func (s pep440Scheme) compare(v *Version, other *Version) int {
+	if result := compareNumeric(v.epoch, other.epoch); result != 0 {
+		return result
+	}
+
+	segments, otherSegments := v.releaseSegments(), other.releaseSegments()
+	for index := 0; index < len(segments) || index < len(otherSegments); index++ {
+		// trailing zeros are insignificant: 1.0 == 1.0.0
+		segment, otherSegment := "0", "0"
+		if index < len(segments) {
+			segment = segments[index]
+		}
+		if index < len(otherSegments) {
+			otherSegment = otherSegments[index]
+		}
+		if result := compareNumeric(segment, otherSegment); result != 0 {
+			return result
+		}
+	}
+
+	if result := compareInt(pep440PreRank(v), pep440PreRank(other)); result != 0 {
+		return result
+	}
+	if v.release != "" && other.release != "" {
+		if result := compareInt(PEP440_RELEASES.index(v.release), PEP440_RELEASES.index(other.release)); result != 0 {
+			return result
+		}
+		if result := compareNumeric(v.build, other.build); result != 0 {
+			return result
+		}
+	}
+
+	if result := compareOptionalNumber(v.post, other.post, false); result != 0 {
+		return result
+	}
+	if result := compareOptionalNumber(v.dev, other.dev, true); result != 0 {
+		return result
+	}
+	return comparePep440Local(v.metadata, other.metadata)
+}
+
+func pep440PreRank(v *Version) int {
+	switch {
+	case v.release == "" && v.post == "" && v.dev != "":
+		return 0
+	case v.release != "":
+		return 1
+	}
+	return 2
+}
+
+// compareOptionalNumber compares two optional segments, where a missing
+// segment sorts either after (dev) or before (post) any number.
+func compareOptionalNumber(number string, otherNumber string, missingIsHigher bool) int {
+	switch {
+	case number == "" && otherNumber == "":
+		return 0
+	case number == "" && missingIsHigher, otherNumber == "" && !missingIsHigher:
+		return 1
+	case number == "", otherNumber == "":
+		return -1
+	}
+	return compareNumeric(number, otherNumber)
+}
+
+func comparePep440Local(local []string, otherLocal []string) int {
+	for index := 0; index < len(local) && index < len(otherLocal); index++ {
+		segment, otherSegment := local[index], otherLocal[index]
+		// numeric segments sort after alphanumeric ones
+		result := 0
+		switch {
+		case isNumeric(segment) && isNumeric(otherSegment):
+			result = compareNumeric(segment, otherSegment)
+		case isNumeric(segment):
+			result = 1
+		case isNumeric(otherSegment):
+			result = -1
+		default:
+			result = strings.Compare(segment, otherSegment)
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return compareInt(len(local), len(otherLocal))
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parsePep440(t *testing.T, text string) *Version {
+	v, length, err := pep440Scheme{}.parse(text)
+	assert.Nil(t, err)
+	assert.Equal(t, len(text), length, fmt.Sprintf("%s should be parsed completely", text))
+	return v
+}
+
+func TestPep440Normalize(t *testing.T) {
+	var tests = []struct {
+		input, expected string
+	}{
+		{"1.0", "1.0"},
+		{"1.2.3.4", "1.2.3.4"},
+		{"1!2.0", "1!2.0"},
+		{"0!2.0", "2.0"},
+		{"1.0.post3", "1.0.post3"},
+		{"1.0-3", "1.0.post3"},
+		{"1.0rev", "1.0.post0"},
+		{"1.0rc1.dev4", "1.0rc1.dev4"},
+		{"1.0-RC-1_DEV_4", "1.0rc1.dev4"},
+		{"1.0alpha", "1.0a0"},
+		{"1.0.preview.2", "1.0rc2"},
+		{"1.0c3", "1.0rc3"},
+		{"v01.02.003", "1.2.3"},
+		{"1.0+ubuntu1", "1.0+ubuntu1"},
+		{"1.0+Ubuntu-1_2", "1.0+ubuntu.1.2"},
+		{"1.0a1.post2.dev3+local.7", "1.0a1.post2.dev3+local.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v := parsePep440(t, tt.input)
+			assert.Equal(t, tt.expected, v.toString())
+		})
+	}
+}
+
+func TestPep440Ordering(t *testing.T) {
+	// the ordering example given by PEP 440, with a few local versions added
+	ordered := []string{
+		"1.0.dev456",
+		"1.0a1",
+		"1.0a2.dev456",
+		"1.0a12.dev456",
+		"1.0a12",
+		"1.0b1.dev456",
+		"1.0b2",
+		"1.0b2.post345.dev456",
+		"1.0b2.post345",
+		"1.0rc1.dev456",
+		"1.0rc1",
+		"1.0",
+		"1.0+abc.5",
+		"1.0+abc.7",
+		"1.0+5",
+		"1.0.post456.dev34",
+		"1.0.post456",
+		"1.1.dev1",
+		"1!0.1",
+	}
+
+	for index := 1; index < len(ordered); index++ {
+		lower := parsePep440(t, ordered[index-1])
+		higher := parsePep440(t, ordered[index])
+		assert.Equal(t, -1, lower.compare(higher), fmt.Sprintf("%s < %s", ordered[index-1], ordered[index]))
+		assert.Equal(t, 1, higher.compare(lower), fmt.Sprintf("%s > %s", ordered[index], ordered[index-1]))
+	}
+}
+
+func TestPep440TrailingZerosAreEqual(t *testing.T) {
+	assert.True(t, parsePep440(t, "1.0").equals(parsePep440(t, "1.0.0")))
+	assert.False(t, parsePep440(t, "1.0").equals(parsePep440(t, "1.0+local")))
+}
+
+func TestPep440Bump(t *testing.T) {
+	var tests = []struct {
+		version, part, preRelease, expected string
+	}{
+		{"1.0", "major", "", "2.0"},
+		{"1.2.3", "minor", "", "1.3.0"},
+		{"1.0", "patch", "", "1.0.1"},
+		{"1!1.2.3rc1+local", "patch", "", "1!1.2.4"},
+		{"1.0", "minor", "dev", "1.1.dev0"},
+		{"1.1.dev0", "", "dev", "1.1.dev1"},
+		{"1.1.dev3", "", "alpha", "1.1a0"},
+		{"1.0a0", "", "alpha", "1.0a1"},
+		{"1.0a1", "", "beta", "1.0b0"},
+		{"1.0rc1.dev4", "", "rc", "1.0rc1"},
+		{"1.0rc1", "", "dev", "1.0rc1.dev0"},
+		{"1.0", "", "post", "1.0.post1"},
+		{"1.0.post1", "", "post", "1.0.post2"},
+		{"1.0.post2.dev1", "", "post", "1.0.post2"},
+		{"1.0rc2", "", "release", "1.0"},
+		{"1.0a1.post2.dev3", "", "release", "1.0"},
+		{"1.0.post2.dev3", "", "release", "1.0.post2"},
+		{"1.0", "", "pre-release", "1.0a0"},
+		{"1.0b3", "", "pre-release", "1.0rc0"},
+		{"1.0rc3", "", "pre-release", "1.0"},
+		{"1.0rc3.dev1", "build", "", "1.0rc3.dev2"},
+		{"1.0.post1", "build", "", "1.0.post2"},
+		{"1.0rc3", "build", "", "1.0rc4"},
+		{"1.0+ubuntu1", "", "dev", "1.0.dev0"},
+	}
+
+	for _, tt := range tests {
+		testname := fmt.Sprintf("%s %s %s", tt.version, tt.part, tt.preRelease)
+		t.Run(testname, func(t *testing.T) {
+			assertNewVersion(t, parsePep440(t, tt.version), tt.part, tt.preRelease, tt.expected)
+		})
+	}
+}
+
+func TestPep440VersionFinder(t *testing.T) {
+	var tests = []struct {
+		line, expected string
+	}{
+		{`version = "1!2.0.post3"`, "1!2.0.post3"},
+		{`__version__ = '1.0rc1.dev4'`, "1.0rc1.dev4"},
+		{`version = "1.0+ubuntu1"`, "1.0+ubuntu1"},
+	}
+
+	finder := NewVersionFinder(pep440Scheme{})
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			v, found := finder.Find(tt.line)
+			assert.True(t, found)
+			assert.Equal(t, tt.expected, v.toString())
+		})
+	}
+}
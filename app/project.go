@@ -0,0 +1,253 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Project is a directory with a dover configuration, along with the version
+// strings found in its versioned files.
+type Project struct {
+	dir     string
+	config  ConfigValues
+	matches *[]*VersionMatch
+}
+
+// Bump describes a version change: the segment to bump (major, minor, patch,
+// build or calver) and/or the pre-release to move to (pre-release, release,
+// post or a pre-release name). Format is the version format to write, the
+// project's version_format when empty.
+type Bump struct {
+	Part       string
+	PreRelease string
+	Format     string
+}
+
+// VersionChange is the update of a single version string.
+type VersionChange struct {
+	Match *VersionMatch
+	Old   string
+	New   string
+}
+
+// Plan is the result of a Bump, ready to be applied to the project.
+type Plan struct {
+	Bump      Bump
+	Current   *Version
+	Next      *Version
+	Changes   []VersionChange
+	Changelog *ChangelogRelease
+}
+
+// LoadProject reads the dover configuration in dir and searches the
+// versioned files for their version strings.
+func LoadProject(dir string) (*Project, error) {
+	cfg, err := configValues(dir)
+	if err != nil {
+		return nil, err
+	}
+	return newProject(cfg)
+}
+
+// FindProject is LoadProject for the closest of dir and its parents that has
+// a dover configuration. The search stops at the root of the git repository.
+func FindProject(dir string) (*Project, error) {
+	cfg, err := discoverConfigValues(dir)
+	if err != nil {
+		return nil, err
+	}
+	return newProject(cfg)
+}
+
+// LoadProjectConfig reads the dover configuration in configFile, with the
+// versioned files relative to dir, or to the directory of configFile when
+// dir is empty.
+func LoadProjectConfig(configFile string, dir string) (*Project, error) {
+	if dir == "" {
+		dir = filepath.Dir(configFile)
+	}
+	if !fileExists(configFile) {
+		return nil, &ConfigError{Err: fmt.Errorf("no such config file: %s", configFile)}
+	}
+	cfg, err := configFileValues(configFile, dir)
+	if errors.Is(err, errNoDoverConfig) {
+		return nil, &ConfigError{File: configFile, Err: errors.New("no dover configuration")}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newProject(cfg)
+}
+
+func newProject(cfg ConfigValues) (*Project, error) {
+	matches, err := getAllVersionStringMatches(cfg.dir, cfg.files, cfg.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Project{dir: cfg.dir, config: cfg, matches: matches}, nil
+}
+
+// Dir is the directory the versioned files are relative to.
+func (p *Project) Dir() string {
+	return p.dir
+}
+
+// Name is the name of the component, empty for the project itself.
+func (p *Project) Name() string {
+	return p.config.name
+}
+
+// Components are the names of the project's components, the parts of the
+// project that each have a version of their own.
+func (p *Project) Components() []string {
+	names := []string{}
+	for _, component := range p.config.components {
+		names = append(names, component.name)
+	}
+	return names
+}
+
+// Component is the project's component with the given name, configured in
+// its own `[dover.components.<name>]` section.
+func (p *Project) Component(name string) (*Project, error) {
+	for _, component := range p.config.components {
+		if component.name == name {
+			return newProject(component)
+		}
+	}
+	if len(p.config.components) == 0 {
+		return nil, &ConfigError{Err: fmt.Errorf("no such component: %s, the project has no components", name)}
+	}
+	return nil, &ConfigError{Err: fmt.Errorf("no such component: %s, the components are %s", name, strings.Join(p.Components(), ", "))}
+}
+
+// Format is the project's configured version format.
+func (p *Project) Format() string {
+	return p.config.format
+}
+
+// Matches are all the version strings found in the versioned files.
+func (p *Project) Matches() []*VersionMatch {
+	return *p.matches
+}
+
+// Current is the project's version. It is an error for the versioned files
+// to disagree on the version.
+func (p *Project) Current() (*Version, error) {
+	if len(*p.matches) == 0 {
+		return nil, &NoVersionError{Files: versionedFilePaths(p.config.files)}
+	}
+	if !assertVersionMatchConsistency(p.matches) {
+		return nil, &InconsistentVersionError{Matches: *p.matches}
+	}
+	return (*p.matches)[0].version, nil
+}
+
+// Plan works out the new version and every change needed to get there,
+// without touching any files.
+func (p *Project) Plan(bump Bump) (*Plan, error) {
+	if bump.Format == "" {
+		bump.Format = p.config.format
+	}
+
+	current, err := p.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := Plan{Bump: bump, Current: current}
+	for _, match := range *p.matches {
+		next, err := match.version.bump(bump.Part, bump.PreRelease)
+		if err != nil {
+			return nil, err
+		}
+		newVersion, err := next.Format(bump.Format)
+		if err != nil {
+			return nil, err
+		}
+		plan.Next = &next
+		plan.Changes = append(plan.Changes, VersionChange{
+			Match: match,
+			Old:   match.version.format(bump.Format),
+			New:   newVersion,
+		})
+	}
+
+	if p.config.changelog != "" {
+		plan.Changelog, err = p.changelogRelease(&plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &plan, nil
+}
+
+// Apply writes the plan's changes to the versioned files and reloads the
+// project's version strings. The files are all updated or, if anything goes
+// wrong, none of them are. A plan whose changelog section has nothing to add
+// is not applied.
+func (p *Project) Apply(plan *Plan) error {
+	if plan.Changelog != nil && plan.Changelog.err != nil {
+		return plan.Changelog.err
+	}
+	updates, err := prepareFileUpdates(p.dir, plan.Changes)
+	if err != nil {
+		return err
+	}
+	if plan.Changelog != nil {
+		updates, err = prepareChangelogUpdate(p.dir, updates, plan.Changelog)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeFileUpdates(updates)
+	if err != nil {
+		return err
+	}
+
+	err = p.reloadConfig(updates)
+	if err != nil {
+		return err
+	}
+	matches, err := getAllVersionStringMatches(p.dir, p.config.files, p.config.scheme)
+	if err != nil {
+		return err
+	}
+	p.matches = matches
+	return nil
+}
+
+// reloadConfig reads the config again when it was one of the updated files,
+// as a config that keeps the version, like .bumpversion.cfg, searches the
+// versioned files for the version it had.
+func (p *Project) reloadConfig(updates []*fileUpdate) error {
+	configPath, err := filepath.EvalSymlinks(p.config.configFile)
+	if err != nil {
+		return err
+	}
+	configPath, err = filepath.Abs(configPath)
+	if err != nil {
+		return err
+	}
+	for _, update := range updates {
+		if updatePath, _ := filepath.Abs(update.path); updatePath != configPath {
+			continue
+		}
+		cfg, err := configFileValues(p.config.configFile, p.dir)
+		if err != nil {
+			return err
+		}
+		for _, component := range cfg.components {
+			if component.name == p.config.name {
+				cfg = component
+			}
+		}
+		p.config = cfg
+		return nil
+	}
+	return nil
+}
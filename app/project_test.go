@@ -0,0 +1,279 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProjectTestSuite struct {
+	suite.Suite
+	tempDir string
+}
+
+func (suite *ProjectTestSuite) writeFile(name, content string) {
+	file := filepath.Join(suite.tempDir, name)
+	err := os.WriteFile(file, []byte(content), 0666)
+	suite.Nil(err)
+}
+
+func (suite *ProjectTestSuite) readFile(name string) string {
+	content, err := os.ReadFile(filepath.Join(suite.tempDir, name))
+	suite.Nil(err)
+	return string(content)
+}
+
+func (suite *ProjectTestSuite) SetupTest() {
+	suite.tempDir, _ = os.MkdirTemp("", "gotest-*")
+	suite.writeFile(".dover", `[dover]
+versioned_files = [
+	"coding.go",
+	"overhill.py"
+]
+`)
+	suite.writeFile("coding.go", "package coding\n\nconst VERSION = \"0.1.0-alpha.0\"\n")
+	suite.writeFile("overhill.py", "__version__ = \"0.1.0-alpha.0\"\n")
+}
+
+func (suite *ProjectTestSuite) TearDownTest() {
+	os.RemoveAll(suite.tempDir)
+}
+
+func (suite *ProjectTestSuite) TestLoadProject() {
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+	suite.Equal(2, len(project.Matches()))
+	suite.Equal("coding.go", project.Matches()[0].File())
+	suite.Equal(3, project.Matches()[0].Line())
+
+	current, err := project.Current()
+	suite.Nil(err)
+	suite.Equal("0.1.0-alpha.0", current.String())
+}
+
+func (suite *ProjectTestSuite) TestLoadProjectWithoutConfig() {
+	os.Remove(filepath.Join(suite.tempDir, ".dover"))
+
+	_, err := LoadProject(suite.tempDir)
+	suite.Equal("unable to find dover configuration", fmt.Sprint(err))
+}
+
+func (suite *ProjectTestSuite) TestLoadProjectConfig() {
+	configFile := filepath.Join(suite.tempDir, "ci.toml")
+	suite.writeFile("ci.toml", "[dover]\nversioned_files = [\"overhill.py\"]\n")
+
+	project, err := LoadProjectConfig(configFile, "")
+	suite.Nil(err)
+	suite.Equal(suite.tempDir, project.Dir())
+	suite.Equal(1, len(project.Matches()))
+
+	suite.writeFile("ci.toml", "[tool.other]\n")
+	_, err = LoadProjectConfig(configFile, suite.tempDir)
+	suite.EqualError(err, configFile+": no dover configuration")
+
+	_, err = LoadProjectConfig(filepath.Join(suite.tempDir, "missing.toml"), "")
+	suite.EqualError(err, "no such config file: "+filepath.Join(suite.tempDir, "missing.toml"))
+}
+
+func (suite *ProjectTestSuite) TestInconsistentVersions() {
+	suite.writeFile("overhill.py", "__version__ = \"0.2.0\"\n")
+
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	_, err = project.Current()
+	suite.Equal("versions do not match across all files", fmt.Sprint(err))
+	suite.IsType(&InconsistentVersionError{}, err)
+	suite.Equal(2, len(err.(*InconsistentVersionError).Matches))
+
+	_, err = project.Plan(Bump{Part: "minor"})
+	suite.NotNil(err)
+}
+
+func (suite *ProjectTestSuite) TestPlan() {
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	plan, err := project.Plan(Bump{PreRelease: "beta"})
+	suite.Nil(err)
+	suite.Equal("0.1.0-beta.0", plan.Next.String())
+	suite.Equal(2, len(plan.Changes))
+	suite.Equal("0.1.0.alpha.0", plan.Changes[0].Old)
+	suite.Equal("0.1.0.beta.0", plan.Changes[0].New)
+
+	// planning does not touch the files
+	suite.Equal("__version__ = \"0.1.0-alpha.0\"\n", suite.readFile("overhill.py"))
+}
+
+func (suite *ProjectTestSuite) TestPlanWithFormat() {
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	plan, err := project.Plan(Bump{Part: "minor", Format: "000"})
+	suite.Nil(err)
+	suite.Equal("0.2.0", plan.Changes[0].New)
+
+	_, err = project.Plan(Bump{Part: "minor", Format: "000-X"})
+	suite.NotNil(err)
+}
+
+func (suite *ProjectTestSuite) TestPlanWithInvalidRelease() {
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	_, err = project.Plan(Bump{PreRelease: "dev"})
+	suite.NotNil(err)
+}
+
+func (suite *ProjectTestSuite) TestApply() {
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	plan, err := project.Plan(Bump{Part: "patch", Format: CANONICAL_FORMAT})
+	suite.Nil(err)
+
+	err = project.Apply(plan)
+	suite.Nil(err)
+	suite.Equal("package coding\n\nconst VERSION = \"0.1.1\"\n", suite.readFile("coding.go"))
+	suite.Equal("__version__ = \"0.1.1\"\n", suite.readFile("overhill.py"))
+
+	current, err := project.Current()
+	suite.Nil(err)
+	suite.Equal("0.1.1", current.String())
+}
+
+func (suite *ProjectTestSuite) TestApplyAbsolutePaths() {
+	shared := suite.T().TempDir()
+	version := filepath.Join(shared, "VERSION")
+	changelog := filepath.Join(shared, "HISTORY.md")
+	suite.Nil(os.WriteFile(version, []byte("# shared\nversion 0.1.0-alpha.0\n"), 0666))
+	suite.Nil(os.WriteFile(changelog, []byte("## Unreleased\n- shared\n"), 0666))
+	suite.writeFile(".dover", fmt.Sprintf(`[dover]
+versioned_files = ["coding.go", %q]
+changelog = %q
+`, version+":2", changelog))
+
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+	suite.Equal(version, project.Matches()[1].File())
+
+	plan, err := project.Plan(Bump{Part: "minor", Format: CANONICAL_FORMAT})
+	suite.Nil(err)
+	suite.Nil(project.Apply(plan))
+	content, _ := os.ReadFile(version)
+	suite.Equal("# shared\nversion 0.2.0\n", string(content))
+	content, _ = os.ReadFile(changelog)
+	suite.Contains(string(content), "## [0.2.0]")
+}
+
+func (suite *ProjectTestSuite) TestApplyBumpversion() {
+	os.Remove(filepath.Join(suite.tempDir, ".dover"))
+	suite.writeFile(".bumpversion.cfg", "[bumpversion]\ncurrent_version = 0.3.0\n\n[bumpversion:file:setup.py]\n")
+	suite.writeFile("setup.py", "setup(\n    version=\"0.3.0\",\n    install_requires=[\"foo>=2.3.4\"],\n)\n")
+
+	// only the current version is found, not the dependency's
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+	suite.Equal([]string{"setup.py", ".bumpversion.cfg"}, versionedFilePaths(project.config.files))
+	suite.Equal(2, len(project.Matches()))
+
+	plan, err := project.Plan(Bump{Part: "minor"})
+	suite.Nil(err)
+	suite.Nil(project.Apply(plan))
+	suite.Equal("setup(\n    version=\"0.4.0\",\n    install_requires=[\"foo>=2.3.4\"],\n)\n", suite.readFile("setup.py"))
+	suite.Equal("[bumpversion]\ncurrent_version = 0.4.0\n\n[bumpversion:file:setup.py]\n", suite.readFile(".bumpversion.cfg"))
+
+	// the config is read again for the new current version
+	current, err := project.Current()
+	suite.Nil(err)
+	suite.Equal("0.4.0", current.String())
+}
+
+func (suite *ProjectTestSuite) TestComponents() {
+	suite.writeFile(".dover", `[dover]
+version_format = "000a0"
+versioned_files = ["coding.go"]
+
+[dover.components.web]
+version_format = "000-a.0"
+versioned_files = ["overhill.py"]
+
+[dover.components.api]
+versioned_files = ["coding.go"]
+`)
+
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+	suite.Equal("", project.Name())
+	suite.Equal([]string{"api", "web"}, project.Components())
+
+	api, err := project.Component("api")
+	suite.Nil(err)
+	suite.Equal("api", api.Name())
+	// the format is taken from the top level
+	suite.Equal("000a0", api.Format())
+	suite.Equal("coding.go", api.Matches()[0].File())
+
+	web, err := project.Component("web")
+	suite.Nil(err)
+	suite.Equal("000-a.0", web.Format())
+	suite.Equal("overhill.py", web.Matches()[0].File())
+
+	_, err = project.Component("cli")
+	suite.EqualError(err, "no such component: cli, the components are api, web")
+}
+
+func (suite *ProjectTestSuite) TestComponentsOnly() {
+	suite.writeFile(".dover", `[dover.components.api]
+versioned_files = ["coding.go"]
+
+[dover.components.web]
+versioned_files = ["missing.py"]
+`)
+
+	_, err := LoadProject(suite.tempDir)
+	suite.EqualError(err, ".dover: component web: no such file: missing.py")
+
+	suite.writeFile(".dover", `[dover.components.api]
+versioned_files = ["coding.go"]
+`)
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+	suite.Equal(0, len(project.Matches()))
+
+	_, err = selectComponent(ExecutionArgs{}, project)
+	suite.EqualError(err, "the project is made up of components, pick one with --component: api")
+
+	api, err := selectComponent(ExecutionArgs{component: "api"}, project)
+	suite.Nil(err)
+	suite.Equal(1, len(api.Matches()))
+}
+
+func (suite *ProjectTestSuite) TestComponentDocuments() {
+	suite.writeFile("web.py", "__version__ = \"0.2.0\"\n")
+	suite.writeFile(".dover", `[dover.components.api]
+versioned_files = ["coding.go", "overhill.py"]
+
+[dover.components.web]
+versioned_files = ["overhill.py", "web.py"]
+`)
+
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+	docs := newComponentDocuments(project, "")
+	suite.Equal(2, len(docs))
+	suite.Equal("api", docs[0].Name)
+	suite.True(docs[0].Consistent)
+	suite.Equal("0.1.0.alpha.0", docs[0].Version.Version)
+	suite.Equal("web", docs[1].Name)
+	suite.False(docs[1].Consistent)
+	suite.Nil(docs[1].Version)
+	suite.Equal("inconsistent_versions", docs[1].Error.Type)
+}
+
+func TestRunProjectTestSuite(t *testing.T) {
+	suite.Run(t, new(ProjectTestSuite))
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// releaseLabel is one pre-release name along with its short spelling,
+// e.g. `alpha` and `a`.
+type releaseLabel struct {
+	long  string
+	short string
+}
+
+// releaseLadder is the ordered list of pre-release names a version moves
+// through on its way to a release, e.g. dev -> alpha -> beta -> rc.
+type releaseLadder struct {
+	labels []releaseLabel
+}
+
+var (
+	DEFAULT_RELEASES = &releaseLadder{labels: []releaseLabel{
+		{long: "dev", short: "d"},
+		{long: "alpha", short: "a"},
+		{long: "beta", short: "b"},
+		{long: "rc", short: "rc"},
+	}}
+	RESERVED_RELEASES = []string{"pre-release", "release", "post"}
+	releaseNameRegex  = regexp.MustCompile(`^[A-Za-z]+$`)
+)
+
+func newReleaseLadder(labels []releaseLabel) (*releaseLadder, error) {
+	ladder := releaseLadder{}
+	for _, label := range labels {
+		if label.short == "" {
+			label.short = label.long
+		}
+		for _, name := range []string{label.long, label.short} {
+			if !releaseNameRegex.MatchString(name) {
+				return nil, fmt.Errorf("invalid pre-release name `%s`: only letters are allowed", name)
+			}
+			if IndexOf(&RESERVED_RELEASES, name) != -1 {
+				return nil, fmt.Errorf("invalid pre-release name `%s`: the name is reserved", name)
+			}
+			if ladder.has(name) {
+				return nil, fmt.Errorf("invalid pre-release name `%s`: the name is used twice", name)
+			}
+		}
+		ladder.labels = append(ladder.labels, label)
+	}
+	if len(ladder.labels) == 0 {
+		return nil, errors.New("pre_releases must name at least one pre-release")
+	}
+	return &ladder, nil
+}
+
+// index returns the position of a release, given by either spelling, or -1.
+func (l *releaseLadder) index(release string) int {
+	for index, label := range l.labels {
+		if label.long == release || label.short == release {
+			return index
+		}
+	}
+	return -1
+}
+
+func (l *releaseLadder) has(release string) bool {
+	return l.index(release) != -1
+}
+
+func (l *releaseLadder) longName(release string) string {
+	if index := l.index(release); index != -1 {
+		return l.labels[index].long
+	}
+	return release
+}
+
+func (l *releaseLadder) shortName(release string) string {
+	if index := l.index(release); index != -1 {
+		return l.labels[index].short
+	}
+	return release
+}
+
+func (l *releaseLadder) names() []string {
+	names := []string{}
+	for _, label := range l.labels {
+		names = append(names, label.long)
+	}
+	return names
+}
+
+func (l *releaseLadder) nextRelease(currentRelease string) string {
+	index := l.index(currentRelease)
+	index += 1
+	if index+1 > len(l.labels) {
+		return ""
+	}
+	return l.labels[index].long
+}
+
+func (l *releaseLadder) validateReleaseOrder(currentRelease string, requestedRelease string) error {
+	if !l.has(requestedRelease) {
+		return &ReleaseOrderError{Current: currentRelease, Requested: requestedRelease, Expected: l.names()}
+	}
+	currentIndex := l.index(currentRelease)
+	requestedIndex := l.index(requestedRelease)
+	if requestedIndex < currentIndex {
+		return &ReleaseOrderError{Current: currentRelease, Requested: requestedRelease}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLadder(t *testing.T) *releaseLadder {
+	ladder, err := newReleaseLadder([]releaseLabel{
+		{long: "nightly", short: "n"},
+		{long: "preview"},
+		{long: "rc"},
+	})
+	assert.Nil(t, err)
+	return ladder
+}
+
+func TestReleaseLadderNames(t *testing.T) {
+	ladder := newTestLadder(t)
+
+	assert.Equal(t, []string{"nightly", "preview", "rc"}, ladder.names())
+	assert.Equal(t, "nightly", ladder.longName("n"))
+	assert.Equal(t, "n", ladder.shortName("nightly"))
+	assert.Equal(t, "preview", ladder.shortName("preview"))
+	assert.Equal(t, "snapshot", ladder.longName("snapshot"))
+	assert.False(t, ladder.has("dev"))
+}
+
+func TestReleaseLadderNextRelease(t *testing.T) {
+	ladder := newTestLadder(t)
+
+	assert.Equal(t, "nightly", ladder.nextRelease(""))
+	assert.Equal(t, "preview", ladder.nextRelease("n"))
+	assert.Equal(t, "rc", ladder.nextRelease("preview"))
+	assert.Equal(t, "", ladder.nextRelease("rc"))
+}
+
+func TestReleaseLadderValidateReleaseOrder(t *testing.T) {
+	ladder := newTestLadder(t)
+
+	assert.Nil(t, ladder.validateReleaseOrder("", "nightly"))
+	assert.Nil(t, ladder.validateReleaseOrder("n", "preview"))
+	assert.Nil(t, ladder.validateReleaseOrder("preview", "preview"))
+	assert.NotNil(t, ladder.validateReleaseOrder("rc", "n"))
+	assert.EqualError(t, ladder.validateReleaseOrder("", "dev"), "Unknown pre-release `dev`. Expected one of: nightly, preview, rc.")
+}
+
+func TestInvalidReleaseLadder(t *testing.T) {
+	var tests = []struct {
+		labels   []releaseLabel
+		expected string
+	}{
+		{[]releaseLabel{}, "pre_releases must name at least one pre-release"},
+		{[]releaseLabel{{long: "rc1"}}, "invalid pre-release name `rc1`: only letters are allowed"},
+		{[]releaseLabel{{long: ""}}, "invalid pre-release name ``: only letters are allowed"},
+		{[]releaseLabel{{long: "post"}}, "invalid pre-release name `post`: the name is reserved"},
+		{[]releaseLabel{{long: "preview", short: "p"}, {long: "p"}}, "invalid pre-release name `p`: the name is used twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			_, err := newReleaseLadder(tt.labels)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestCustomReleaseLadderBump(t *testing.T) {
+	scheme := semverScheme{ladder: newTestLadder(t)}
+	v, _, err := scheme.parse("1.2.0")
+	assert.Nil(t, err)
+
+	assertNewVersion(t, v, "", "pre-release", "1.2.0-nightly.0")
+	assertNewVersion(t, v, "", "n", "1.2.0-nightly.0")
+	assertNewVersion(t, v, "minor", "preview", "1.3.0-preview.0")
+
+	nightly, _, _ := scheme.parse("1.2.0-n3")
+	assertNewVersion(t, nightly, "", "nightly", "1.2.0-nightly.4")
+	assertNewVersion(t, nightly, "build", "", "1.2.0-nightly.4")
+	assertNewVersion(t, nightly, "", "pre-release", "1.2.0-preview.0")
+	assert.Equal(t, "1.2.0n3", nightly.format("000a0"))
+}
+
+func TestCustomReleaseLadderPlusSeparator(t *testing.T) {
+	scheme := semverScheme{ladder: newTestLadder(t)}
+
+	// `+` only separates a pre-release when the name is on the ladder
+	v, _, _ := scheme.parse("1.2.0+nightly.2")
+	assert.Equal(t, "1.2.0-nightly.2", v.toString())
+
+	v, _, _ = scheme.parse("1.2.0+dev.2")
+	assert.Equal(t, "1.2.0+dev.2", v.toString())
+}
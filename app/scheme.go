@@ -0,0 +1,102 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	SEMVER_SCHEME = "semver"
+	PEP440_SCHEME = "pep440"
+	CALVER_SCHEME = "calver"
+)
+
+// versionScheme captures everything that differs between the versioning
+// schemes dover understands: what a version looks like, how it is written
+// back out, how it is bumped and how two versions are ordered.
+type versionScheme interface {
+	name() string
+	// pattern is the regex used to spot a version of this scheme in a line.
+	pattern() string
+	// parse reads the version at the start of text and reports how many
+	// bytes of text it used.
+	parse(text string) (*Version, int, error)
+	format(v *Version, f *Formatter) string
+	bump(v *Version, part string, preRelease string) (Version, error)
+	compare(v *Version, other *Version) int
+	// releases is the pre-release ladder versions of this scheme use.
+	releases() *releaseLadder
+}
+
+// semverScheme is dover's default scheme. A nil ladder means the default
+// dev, alpha, beta, rc ladder.
+type semverScheme struct {
+	ladder *releaseLadder
+}
+
+func (s semverScheme) name() string {
+	return SEMVER_SCHEME
+}
+
+func (s semverScheme) pattern() string {
+	return JUST_VERSION
+}
+
+func (s semverScheme) parse(text string) (*Version, int, error) {
+	return parseVersionPrefix(text, s)
+}
+
+func (s semverScheme) format(v *Version, f *Formatter) string {
+	return f.format(v)
+}
+
+func (s semverScheme) bump(v *Version, part string, preRelease string) (Version, error) {
+	nv, err := v.bumpSemVer(part, preRelease)
+	if err != nil {
+		return Version{}, err
+	}
+	if err := checkBumpOrder(v, &nv); err != nil {
+		return Version{}, err
+	}
+	return nv, nil
+}
+
+func (s semverScheme) compare(v *Version, other *Version) int {
+	return v.compareSemVer(other)
+}
+
+func (s semverScheme) releases() *releaseLadder {
+	if s.ladder == nil {
+		return DEFAULT_RELEASES
+	}
+	return s.ladder
+}
+
+func newVersionScheme(cfg ConfigValues) (versionScheme, error) {
+	ladder := DEFAULT_RELEASES
+	if len(cfg.releases) > 0 {
+		var err error
+		ladder, err = newReleaseLadder(cfg.releases)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.schemeName {
+	case "", SEMVER_SCHEME:
+		return semverScheme{ladder: ladder}, nil
+	case PEP440_SCHEME:
+		if len(cfg.releases) > 0 {
+			return nil, errors.New("pre_releases cannot be changed for the pep440 version scheme")
+		}
+		return pep440Scheme{}, nil
+	case CALVER_SCHEME:
+		scheme, err := newCalverScheme(cfg.calverFormat, ladder, time.Now)
+		if err != nil {
+			return nil, err
+		}
+		return scheme, nil
+	}
+	return nil, fmt.Errorf("unknown version_scheme: %s", cfg.schemeName)
+}
@@ -1,82 +1,198 @@
 package app
 
 import (
-	"errors"
+	"fmt"
 	"os"
-	"strconv"
-	"strings"
 )
 
+// VersionMatch is a version found in a versioned file. text is the version
+// as it is written in the file, found at the byte offsets start to end of
+// the line.
+//
+// When the versioned file has a replace template, all of the text its
+// search pattern matched, searchText, is replaced by the template.
 type VersionMatch struct {
-	file    string
-	line    int
-	version *Version
+	file       string
+	line       int
+	start      int
+	end        int
+	text       string
+	version    *Version
+	replace    string
+	search     [2]int
+	searchText string
 }
 
-func newVersionMatch(file string, line int, version *Version) *VersionMatch {
+func newVersionMatch(file string, line int, start int, text string, version *Version) *VersionMatch {
 	vm := VersionMatch{
 		file:    file,
 		line:    line,
+		start:   start,
+		end:     start + len(text),
+		text:    text,
 		version: version,
 	}
 	return &vm
 }
 
-func readVersionSourceFile(filePath string) []string {
+// File is the path of the file the version was found in.
+func (m *VersionMatch) File() string {
+	return m.file
+}
+
+// Line is the line number, counting from 1, the version was found on.
+func (m *VersionMatch) Line() int {
+	return m.line
+}
+
+// Text is the version as it is written in the file.
+func (m *VersionMatch) Text() string {
+	return m.text
+}
+
+// Column is the column, counting from 1, the version starts at.
+func (m *VersionMatch) Column() int {
+	return m.start + 1
+}
+
+// position is where the version is, as `line:column`.
+func (m *VersionMatch) position() string {
+	return fmt.Sprintf("%d:%d", m.line, m.Column())
+}
+
+// Span is the byte offsets of the version within its line.
+func (m *VersionMatch) Span() (int, int) {
+	return m.start, m.end
+}
+
+// Version is the version found, parsed with the project's scheme.
+func (m *VersionMatch) Version() *Version {
+	return m.version
+}
+
+func readVersionSourceFile(filePath string) ([]string, error) {
 	content, err := os.ReadFile(filePath)
-	check(err)
-	return strings.Split(string(content), "\n")
+	if err != nil {
+		return nil, err
+	}
+	return parseTextFile(content).lines, nil
 }
 
-func searchForVersionString(file string, lines []int, fileContent []string) []*VersionMatch {
+// searchForVersionString searches the lines of a versioned file, or only the
+// given line numbers if there are any, for every version on them. When the
+// file has an occurrence only that one of its versions is kept, and it is an
+// error for the file to have fewer. Lines with a `dover:version` marker are
+// always searched and lines marked `dover:ignore` never are.
+func searchForVersionString(file versionedFile, lines []int, fileContent []string, finder *VersionFinder) ([]*VersionMatch, error) {
 	lineMatches := make([]*VersionMatch, 0)
-	finder := NewVersionFinder()
+	filePath, _ := splitFileAndLineNotation(file.path)
+	markers := readLineMarkers(fileContent)
+	markerFinder := finder
+	if file.search == "" {
+		markerFinder = newMarkerFinder(finder.scheme)
+	}
 	for index, line := range fileContent {
-		if len(lines) > 0 && IndexOf(&lines, index) == -1 {
+		if markers.ignored[index] {
+			continue
+		}
+		if len(lines) > 0 && IndexOf(&lines, index+1) == -1 && !markers.tracked[index] {
 			continue
 		}
-		v, found := finder.Find(line)
-		if found {
-			vm := newVersionMatch(file, index, &v)
+		found := finder.findAll(line)
+		if len(found) == 0 && markers.tracked[index] {
+			found = markerFinder.findAll(line)
+		}
+		for _, finding := range found {
+			vm := newVersionMatch(filePath, index+1, finding.start, line[finding.start:finding.end], &finding.version)
+			if file.replace != "" {
+				vm.replace = file.replace
+				vm.search = [2]int{finding.matchStart, finding.matchEnd}
+				vm.searchText = line[finding.matchStart:finding.matchEnd]
+			}
 			lineMatches = append(lineMatches, vm)
 		}
 	}
-	return lineMatches
+	if file.occurrence > 0 {
+		if file.occurrence > len(lineMatches) {
+			return nil, &ConfigError{Err: fmt.Errorf("%s: occurrence %d, but the file has %d versions", filePath, file.occurrence, len(lineMatches))}
+		}
+		return lineMatches[file.occurrence-1 : file.occurrence], nil
+	}
+	return lineMatches, nil
 }
 
-func parseVersionedFileConfig(filePath string) (string, []int) {
-	lines := make([]int, 0)
-	filePath, lineNotation := splitFileAndLineNotation(filePath)
+// newFileFinder is the finder for a versioned file, which uses the file's
+// search pattern if it has one.
+func newFileFinder(file versionedFile, scheme versionScheme) (*VersionFinder, error) {
+	if file.search == "" {
+		return NewVersionFinder(scheme), nil
+	}
+	finder, err := newSearchFinder(file.search, scheme)
+	if err != nil {
+		return nil, &ConfigError{Err: fmt.Errorf("%s: %s", file.path, err)}
+	}
+	return finder, nil
+}
+
+// parseVersionedFileConfig splits a versioned file into its path and the
+// line ranges of its line notation, if it has one.
+func parseVersionedFileConfig(filePath string) (string, []lineRange, error) {
+	path, lineNotation := splitFileAndLineNotation(filePath)
 	if lineNotation == "" {
-		return filePath, lines
+		return path, []lineRange{}, nil
 	}
-	for _, value := range strings.Split(lineNotation, ",") {
-		lineInt, err := strconv.Atoi(value)
-		if err != nil {
-			ExitOnError(errors.New("invalid versioned file:line notation in configuration file"))
-		}
-		lines = append(lines, lineInt)
+	ranges, err := parseLineNotation(lineNotation)
+	if err != nil {
+		return path, nil, &ParseError{Kind: "line notation", Text: filePath, Reason: fmt.Sprintf("(%s)", err)}
 	}
-	return filePath, lines
+	return path, ranges, nil
 }
 
-func getAllVersionStringMatches(files []string) *[]*VersionMatch {
+// getAllVersionStringMatches searches the versioned files, which are relative
+// to dir, for their version strings.
+func getAllVersionStringMatches(dir string, files []versionedFile, scheme versionScheme) (*[]*VersionMatch, error) {
 	allMatches := make([]*VersionMatch, 0)
 	for _, file := range files {
-		filePath, lines := parseVersionedFileConfig(file)
-		content := readVersionSourceFile(filePath)
-		for _, match := range searchForVersionString(filePath, lines, content) {
+		if filePath, keyPath := splitFileAndKeyPath(file.path); keyPath != "" {
+			content, err := os.ReadFile(projectPath(dir, filePath))
+			if err != nil {
+				return nil, err
+			}
+			match, err := searchKeyPath(filePath, keyPath, content, scheme)
+			if err != nil {
+				return nil, err
+			}
 			allMatches = append(allMatches, match)
+			continue
+		}
+
+		filePath, ranges, err := parseVersionedFileConfig(file.path)
+		if err != nil {
+			return nil, err
+		}
+		finder, err := newFileFinder(file, scheme)
+		if err != nil {
+			return nil, err
+		}
+		content, err := readVersionSourceFile(projectPath(dir, filePath))
+		if err != nil {
+			return nil, err
+		}
+		lines := resolveLineRanges(ranges, lineCount(content))
+		matches, err := searchForVersionString(file, lines, content, finder)
+		if err != nil {
+			return nil, err
 		}
+		allMatches = append(allMatches, matches...)
 	}
 
-	return &allMatches
+	return &allMatches, nil
 }
 
 func assertVersionMatchConsistency(matches *[]*VersionMatch) bool {
 	var rootVersion *Version = (*matches)[0].version
 	for _, m := range *matches {
-		if !m.version.equals(rootVersion) {
+		if !m.version.identical(rootVersion) {
 			return false
 		}
 	}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchOccurrence(t *testing.T) {
+	lines := []string{"version 0.3.0 (was version 0.2.0)", "version = \"0.3.0\""}
+	finder := NewVersionFinder(semverScheme{})
+
+	matches, err := searchForVersionString(versionedFile{path: "CHANGES"}, []int{}, lines, finder)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(matches))
+	assert.Equal(t, "1:9", matches[0].position())
+	assert.Equal(t, "1:28", matches[1].position())
+	assert.Equal(t, "2:12", matches[2].position())
+
+	matches, err = searchForVersionString(versionedFile{path: "CHANGES", occurrence: 3}, []int{}, lines, finder)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(matches))
+	assert.Equal(t, "2:12", matches[0].position())
+
+	_, err = searchForVersionString(versionedFile{path: "CHANGES", occurrence: 4}, []int{}, lines, finder)
+	assert.IsType(t, &ConfigError{}, err)
+	assert.EqualError(t, err, "CHANGES: occurrence 4, but the file has 3 versions")
+}
+
+func TestVersionMatchConsistency(t *testing.T) {
+	finder := NewVersionFinder(semverScheme{})
+	a, _ := searchForVersionString(versionedFile{path: "a.go"}, []int{}, []string{`VERSION = "1.0.0+build.1"`}, finder)
+	b, _ := searchForVersionString(versionedFile{path: "b.go"}, []int{}, []string{`VERSION = "1.0.0+build.2"`}, finder)
+	c, _ := searchForVersionString(versionedFile{path: "c.go"}, []int{}, []string{`VERSION = "1.0.0+build.1"`}, finder)
+
+	assert.False(t, assertVersionMatchConsistency(&[]*VersionMatch{a[0], b[0]}))
+	assert.True(t, assertVersionMatchConsistency(&[]*VersionMatch{a[0], c[0]}))
+}
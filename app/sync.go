@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The ways of picking the version to sync the versioned files to.
+const (
+	SYNC_HIGHEST  = "highest"
+	SYNC_MAJORITY = "majority"
+	SYNC_SOURCE   = "source"
+)
+
+// Sync says which version to bring every versioned file to: To when it is
+// set, or else the version picked by Strategy. Without a Strategy the
+// project's source_of_truth file is used if it has one, and the version most
+// files have if not. Format is the version format to write, the project's
+// version_format when empty.
+type Sync struct {
+	To       string
+	Strategy string
+	Format   string
+}
+
+// SyncPlan works out the changes that bring the versioned files whose
+// version is out of line to the synced version. The plan's Next is that
+// version and it has no Current.
+func (p *Project) SyncPlan(sync Sync) (*Plan, error) {
+	if sync.Format == "" {
+		sync.Format = p.config.format
+	}
+	if len(*p.matches) == 0 {
+		return nil, &NoVersionError{Files: versionedFilePaths(p.config.files)}
+	}
+
+	next, err := p.syncVersion(sync)
+	if err != nil {
+		return nil, err
+	}
+	newVersion, err := next.Format(sync.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := Plan{Bump: Bump{Format: sync.Format}, Next: next}
+	for _, match := range *p.matches {
+		if match.version.identical(next) {
+			continue
+		}
+		plan.Changes = append(plan.Changes, VersionChange{
+			Match: match,
+			Old:   match.version.format(sync.Format),
+			New:   newVersion,
+		})
+	}
+	return &plan, nil
+}
+
+func (p *Project) syncVersion(sync Sync) (*Version, error) {
+	if sync.To != "" {
+		v, length, err := p.config.scheme.parse(sync.To)
+		if err != nil || length != len(sync.To) {
+			return nil, &ParseError{Kind: "version", Text: sync.To}
+		}
+		return v, nil
+	}
+
+	strategy := sync.Strategy
+	if strategy == "" && p.config.sourceOfTruth != "" {
+		strategy = SYNC_SOURCE
+	} else if strategy == "" {
+		strategy = SYNC_MAJORITY
+	}
+
+	switch strategy {
+	case SYNC_HIGHEST:
+		highest := (*p.matches)[0].version
+		for _, match := range *p.matches {
+			if match.version.compare(highest) > 0 {
+				highest = match.version
+			}
+		}
+		return highest, nil
+	case SYNC_MAJORITY:
+		return majorityVersion(*p.matches)
+	case SYNC_SOURCE:
+		if p.config.sourceOfTruth == "" {
+			return nil, &ConfigError{Err: fmt.Errorf("no source_of_truth file is configured")}
+		}
+		for _, match := range *p.matches {
+			if match.file == p.config.sourceOfTruth {
+				return match.version, nil
+			}
+		}
+		return nil, &ConfigError{Err: fmt.Errorf("source_of_truth %s has no version", p.config.sourceOfTruth)}
+	}
+	return nil, &ParseError{Kind: "sync strategy", Text: strategy, Reason: fmt.Sprintf("(expected %s, %s or %s)", SYNC_HIGHEST, SYNC_MAJORITY, SYNC_SOURCE)}
+}
+
+// majorityVersion is the version found the most times. It is an error for
+// two versions to be found as many times.
+func majorityVersion(matches []*VersionMatch) (*Version, error) {
+	versions := []*Version{}
+	counts := []int{}
+	for _, match := range matches {
+		index := -1
+		for i, v := range versions {
+			if v.identical(match.version) {
+				index = i
+			}
+		}
+		if index == -1 {
+			versions = append(versions, match.version)
+			counts = append(counts, 0)
+			index = len(versions) - 1
+		}
+		counts[index]++
+	}
+
+	best := 0
+	tied := []string{versions[0].toString()}
+	for index := 1; index < len(versions); index++ {
+		switch {
+		case counts[index] > counts[best]:
+			best = index
+			tied = []string{versions[index].toString()}
+		case counts[index] == counts[best]:
+			tied = append(tied, versions[index].toString())
+		}
+	}
+	if len(tied) > 1 {
+		listed := strings.Join(tied[:len(tied)-1], ", ") + " and " + tied[len(tied)-1]
+		return nil, &InconsistentVersionError{
+			Matches: matches,
+			Reason:  fmt.Sprintf("no version is in most files, %s are each in %d, use --to or --highest", listed, counts[best]),
+		}
+	}
+	return versions[best], nil
+}
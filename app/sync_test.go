@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+)
+
+func (suite *ProjectTestSuite) writeSyncProject(sourceOfTruth string) {
+	config := `[dover]
+version_format = "000"
+versioned_files = ["a.py", "b.py", "c.py"]
+`
+	if sourceOfTruth != "" {
+		config += fmt.Sprintf("source_of_truth = \"%s\"\n", sourceOfTruth)
+	}
+	suite.writeFile(".dover", config)
+	suite.writeFile("a.py", "VERSION = \"1.2.0\"\n")
+	suite.writeFile("b.py", "VERSION = \"1.2.0\"\n")
+	suite.writeFile("c.py", "VERSION = \"1.3.0\"\n")
+}
+
+func (suite *ProjectTestSuite) TestSyncPlan() {
+	suite.writeSyncProject("")
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	tests := []struct {
+		sync    Sync
+		next    string
+		changed []string
+	}{
+		{Sync{}, "1.2.0", []string{"c.py"}},
+		{Sync{Strategy: SYNC_MAJORITY}, "1.2.0", []string{"c.py"}},
+		{Sync{Strategy: SYNC_HIGHEST}, "1.3.0", []string{"a.py", "b.py"}},
+		{Sync{To: "2.0.0"}, "2.0.0", []string{"a.py", "b.py", "c.py"}},
+		{Sync{To: "1.3.0", Strategy: SYNC_MAJORITY}, "1.3.0", []string{"a.py", "b.py"}},
+	}
+
+	for _, test := range tests {
+		plan, err := project.SyncPlan(test.sync)
+		suite.Nil(err)
+		suite.Nil(plan.Current)
+		suite.Equal(test.next, plan.Next.String())
+
+		changed := []string{}
+		for _, change := range plan.Changes {
+			changed = append(changed, change.Match.File())
+			suite.Equal(test.next, change.New)
+		}
+		suite.Equal(test.changed, changed)
+	}
+}
+
+func (suite *ProjectTestSuite) TestSyncPlanSourceOfTruth() {
+	suite.writeSyncProject("c.py")
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	plan, err := project.SyncPlan(Sync{})
+	suite.Nil(err)
+	suite.Equal("1.3.0", plan.Next.String())
+	suite.Equal(2, len(plan.Changes))
+
+	plan, err = project.SyncPlan(Sync{Strategy: SYNC_MAJORITY})
+	suite.Nil(err)
+	suite.Equal("1.2.0", plan.Next.String())
+}
+
+func (suite *ProjectTestSuite) TestSyncPlanErrors() {
+	suite.writeSyncProject("")
+	suite.writeFile("a.py", "VERSION = \"1.1.0\"\n")
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	_, err = project.SyncPlan(Sync{})
+	suite.IsType(&InconsistentVersionError{}, err)
+	suite.Equal("no version is in most files, 1.1.0, 1.2.0 and 1.3.0 are each in 1, use --to or --highest", fmt.Sprint(err))
+	suite.Equal(EXIT_INCONSISTENT_VERSIONS, exitCode(err))
+
+	_, err = project.SyncPlan(Sync{Strategy: "newest"})
+	suite.IsType(&ParseError{}, err)
+	suite.Equal("invalid sync strategy: newest (expected highest, majority or source)", fmt.Sprint(err))
+
+	_, err = project.SyncPlan(Sync{To: "1.x"})
+	suite.IsType(&ParseError{}, err)
+
+	_, err = project.SyncPlan(Sync{Strategy: SYNC_SOURCE})
+	suite.IsType(&ConfigError{}, err)
+
+	for _, file := range []string{"a.py", "b.py", "c.py"} {
+		suite.writeFile(file, "VERSION = None\n")
+	}
+	project, err = LoadProject(suite.tempDir)
+	suite.Nil(err)
+	_, err = project.SyncPlan(Sync{})
+	suite.IsType(&NoVersionError{}, err)
+	suite.Equal(EXIT_CONFIG_ERROR, exitCode(err))
+}
+
+func (suite *ProjectTestSuite) TestSyncApply() {
+	suite.writeSyncProject("")
+	project, err := LoadProject(suite.tempDir)
+	suite.Nil(err)
+
+	plan, err := project.SyncPlan(Sync{})
+	suite.Nil(err)
+	suite.Nil(project.Apply(plan))
+	suite.Equal("VERSION = \"1.2.0\"\n", suite.readFile("c.py"))
+
+	current, err := project.Current()
+	suite.Nil(err)
+	suite.Equal("1.2.0", current.String())
+}
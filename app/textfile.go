@@ -0,0 +1,79 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+)
+
+const UTF8_BOM = "\uFEFF"
+
+// textFile is a versioned file split into lines. It remembers the BOM and
+// the ending of every line, so that a file written back out is byte for byte
+// the same apart from the lines that were changed, whether it uses LF, CRLF
+// or a mix of both, and whether or not it ends with a newline.
+//
+// The lines are numbered as strings.Split(content, "\n") numbers them: a
+// file ending with a newline has a last, empty line.
+type textFile struct {
+	bom     bool
+	lines   []string
+	endings []string
+}
+
+func parseTextFile(content []byte) *textFile {
+	f := textFile{}
+	if bytes.HasPrefix(content, []byte(UTF8_BOM)) {
+		f.bom = true
+		content = content[len(UTF8_BOM):]
+	}
+
+	lines := strings.Split(string(content), "\n")
+	for index, line := range lines {
+		ending := "\n"
+		if index == len(lines)-1 {
+			ending = ""
+		} else if strings.HasSuffix(line, "\r") {
+			line = strings.TrimSuffix(line, "\r")
+			ending = "\r\n"
+		}
+		f.lines = append(f.lines, line)
+		f.endings = append(f.endings, ending)
+	}
+	return &f
+}
+
+func (f *textFile) bytes() []byte {
+	var b bytes.Buffer
+	if f.bom {
+		b.WriteString(UTF8_BOM)
+	}
+	for index, line := range f.lines {
+		b.WriteString(line)
+		b.WriteString(f.endings[index])
+	}
+	return b.Bytes()
+}
+
+// insertLines adds lines before the line at index, ending them the way the
+// file's lines end.
+func (f *textFile) insertLines(index int, lines []string) {
+	ending := "\n"
+	for _, lineEnding := range f.endings {
+		if lineEnding != "" {
+			ending = lineEnding
+			break
+		}
+	}
+	endings := []string{}
+	for range lines {
+		endings = append(endings, ending)
+	}
+	if index == len(f.lines) && index > 0 {
+		// after a last line that has no line ending
+		f.endings[index-1] = ending
+		endings[len(endings)-1] = ""
+	}
+
+	f.lines = append(f.lines[:index], append(lines, f.lines[index:]...)...)
+	f.endings = append(f.endings[:index], append(endings, f.endings[index:]...)...)
+}
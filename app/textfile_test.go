@@ -0,0 +1,33 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTextFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		bom     bool
+		lines   []string
+		endings []string
+	}{
+		{"lf", "a\nb\n", false, []string{"a", "b", ""}, []string{"\n", "\n", ""}},
+		{"crlf", "a\r\nb\r\n", false, []string{"a", "b", ""}, []string{"\r\n", "\r\n", ""}},
+		{"mixed", "a\r\nb\nc", false, []string{"a", "b", "c"}, []string{"\r\n", "\n", ""}},
+		{"no trailing newline", "a\nb", false, []string{"a", "b"}, []string{"\n", ""}},
+		{"bom", UTF8_BOM + "a\n", true, []string{"a", ""}, []string{"\n", ""}},
+		{"empty", "", false, []string{""}, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := parseTextFile([]byte(tt.content))
+			assert.Equal(t, tt.bom, f.bom)
+			assert.Equal(t, tt.lines, f.lines)
+			assert.Equal(t, tt.endings, f.endings)
+			assert.Equal(t, tt.content, string(f.bytes()))
+		})
+	}
+}
@@ -1,47 +1,177 @@
 package app
 
 import (
-	"bufio"
+	"fmt"
 	"os"
-	"regexp"
+	"path/filepath"
 	"strings"
 )
 
-func writeVersionUpdate(filePath string, lineNo int, newVersion string) {
-	content, err := os.ReadFile(filePath)
-	check(err)
+// renameFile is swapped out in tests to simulate a failed write.
+var renameFile = os.Rename
 
-	lines := strings.Split(string(content), "\n")
-	lineTotal := len(lines)
+// fileUpdate is the new content of one versioned file. The original content
+// is kept so the file can be put back if the update is rolled back.
+//
+// path is the file itself rather than a symlink to it, so that renaming the
+// new content into place updates the file and leaves the symlink alone.
+type fileUpdate struct {
+	path     string
+	info     os.FileInfo
+	original []byte
+	content  []byte
+	tempPath string
+	replaced bool
+}
 
-	rx := regexp.MustCompile(JUST_VERSION)
+// updateVersionLine replaces the matched version, and only that, with
+// newVersion. The line must still hold the version where it was found.
+//
+// With a replace template, all the text the search pattern matched is
+// replaced by the template instead.
+func updateVersionLine(lines []string, match *VersionMatch, newVersion string) error {
+	start, end, text := match.start, match.end, match.text
+	if match.replace != "" {
+		start, end, text = match.search[0], match.search[1], match.searchText
+		newVersion = strings.ReplaceAll(match.replace, VERSION_PLACEHOLDER, newVersion)
+	}
 
-	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
-	ExitOnError(err)
+	index := match.line - 1
+	line := lines[index]
+	if end > len(line) || line[start:end] != text {
+		return fmt.Errorf("%s:%d no longer has the version %s", match.file, match.line, match.text)
+	}
+	lines[index] = line[:start] + newVersion + line[end:]
+	return nil
+}
 
-	err = file.Truncate(0)
-	ExitOnError(err)
+// prepareFileUpdates reads every file the changes touch, relative to dir,
+// and works out its new content in memory.
+func prepareFileUpdates(dir string, changes []VersionChange) ([]*fileUpdate, error) {
+	updates := []*fileUpdate{}
+	files := map[string]*textFile{}
+	// the same version can be listed twice, e.g. through a symlink
+	replaced := map[string]bool{}
 
-	_, err = file.Seek(0, 0)
-	ExitOnError(err)
+	for _, change := range changes {
+		filePath, err := filepath.EvalSymlinks(projectPath(dir, change.Match.file))
+		if err != nil {
+			return nil, err
+		}
+		if _, found := files[filePath]; !found {
+			info, err := os.Stat(filePath)
+			if err != nil {
+				return nil, err
+			}
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return nil, err
+			}
+			updates = append(updates, &fileUpdate{path: filePath, info: info, original: content})
+			files[filePath] = parseTextFile(content)
+		}
+		lines := files[filePath].lines
+		if change.Match.line < 1 || change.Match.line > len(lines) {
+			return nil, fmt.Errorf("%s has no line %d", change.Match.file, change.Match.line)
+		}
+		key := fmt.Sprintf("%s:%d:%d", filePath, change.Match.line, change.Match.start)
+		if replaced[key] {
+			continue
+		}
+		replaced[key] = true
+		err = updateVersionLine(lines, change.Match, change.New)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, update := range updates {
+		update.content = files[update.path].bytes()
+	}
+	return updates, nil
+}
 
-	defer file.Close()
+// writeTempFile writes content to a new file next to path, with the same
+// mode, special bits included, and owner as info, so that it can be renamed
+// over path.
+func writeTempFile(path string, content []byte, info os.FileInfo) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".dover-*")
+	if err != nil {
+		return "", err
+	}
+	tempPath := file.Name()
 
-	writer := bufio.NewWriter(file)
-	for index, line := range lines {
-		lineSeparator := "\n"
-		if index+1 == lineTotal {
-			lineSeparator = ""
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	// the mode goes on after the owner, as a change of owner clears the
+	// setuid and setgid bits
+	if err == nil {
+		err = copyOwner(tempPath, info)
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return "", err
+	}
+	return tempPath, nil
+}
+
+// writeFileUpdates applies all of the updates or none of them. Every new
+// file is written to a temp file first, and only when they all have been
+// written are they renamed into place. If any step fails the files that were
+// already replaced get their original content back.
+func writeFileUpdates(updates []*fileUpdate) error {
+	var err error
+	for _, update := range updates {
+		update.tempPath, err = writeTempFile(update.path, update.content, update.info)
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		for _, update := range updates {
+			err = renameFile(update.tempPath, update.path)
+			if err != nil {
+				break
+			}
+			update.replaced = true
+		}
+	}
+
+	if err != nil {
+		return rollbackFileUpdates(updates, err)
+	}
+	return nil
+}
+
+func rollbackFileUpdates(updates []*fileUpdate, cause error) error {
+	failed := []string{}
+	for _, update := range updates {
+		if update.tempPath != "" && !update.replaced {
+			os.Remove(update.tempPath)
+		}
+		if !update.replaced {
+			continue
 		}
-		if index == lineNo {
-			newLine := rx.ReplaceAllString(line, newVersion)
-			_, err = writer.WriteString(newLine + lineSeparator)
-			ExitOnError(err)
-		} else {
-			_, err = writer.WriteString(line + lineSeparator)
-			ExitOnError(err)
+		tempPath, err := writeTempFile(update.path, update.original, update.info)
+		if err == nil {
+			err = os.Rename(tempPath, update.path)
+		}
+		if err != nil {
+			failed = append(failed, update.path)
 		}
 	}
-	writer.Flush()
 
+	if len(failed) > 0 {
+		return fmt.Errorf("%w, and these files could not be restored: %s", cause, strings.Join(failed, ", "))
+	}
+	return fmt.Errorf("%w, no files have been changed", cause)
 }
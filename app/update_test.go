@@ -0,0 +1,258 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.Nil(t, err)
+	}
+}
+
+func readTestFile(t *testing.T, dir string, name string) string {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	assert.Nil(t, err)
+	return string(content)
+}
+
+func testVersionChanges(t *testing.T, dir string, paths []string, newVersion string) []VersionChange {
+	files := []versionedFile{}
+	for _, path := range paths {
+		files = append(files, versionedFile{path: path})
+	}
+	return testFileVersionChanges(t, dir, files, newVersion)
+}
+
+func testFileVersionChanges(t *testing.T, dir string, files []versionedFile, newVersion string) []VersionChange {
+	matches, err := getAllVersionStringMatches(dir, files, semverScheme{})
+	assert.Nil(t, err)
+	changes := []VersionChange{}
+	for _, match := range *matches {
+		changes = append(changes, VersionChange{Match: match, Old: match.version.toString(), New: newVersion})
+	}
+	return changes
+}
+
+func TestPrepareFileUpdates(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.go":   "const VERSION = \"0.1.0\"\nconst Version2 = \"0.1.0\"\n",
+		"README.md": "version: 0.1.0",
+	})
+
+	changes := testVersionChanges(t, dir, []string{"main.go", "README.md"}, "0.2.0")
+	updates, err := prepareFileUpdates(dir, changes)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(updates))
+	assert.Equal(t, "const VERSION = \"0.2.0\"\nconst Version2 = \"0.2.0\"\n", string(updates[0].content))
+	assert.Equal(t, "version: 0.2.0", string(updates[1].content))
+
+	// nothing is written until the updates are applied
+	assert.Equal(t, "version: 0.1.0", readTestFile(t, dir, "README.md"))
+}
+
+func TestWriteFileUpdates(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.go":   "const VERSION = \"0.1.0\"\n",
+		"README.md": "version: 0.1.0",
+	})
+	os.Chmod(filepath.Join(dir, "main.go"), 0755)
+
+	changes := testVersionChanges(t, dir, []string{"main.go", "README.md"}, "0.2.0")
+	updates, err := prepareFileUpdates(dir, changes)
+	assert.Nil(t, err)
+
+	err = writeFileUpdates(updates)
+	assert.Nil(t, err)
+	assert.Equal(t, "const VERSION = \"0.2.0\"\n", readTestFile(t, dir, "main.go"))
+	assert.Equal(t, "version: 0.2.0", readTestFile(t, dir, "README.md"))
+
+	info, _ := os.Stat(filepath.Join(dir, "main.go"))
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	entries, _ := os.ReadDir(dir)
+	assert.Equal(t, 2, len(entries))
+}
+
+func TestWriteFileUpdatesRollback(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a.go": "const VERSION = \"0.1.0\"\n",
+		"b.go": "const VERSION = \"0.1.0\"\n",
+		"c.go": "const VERSION = \"0.1.0\"\n",
+	})
+
+	renames := 0
+	renameFile = func(from string, to string) error {
+		renames++
+		if renames == 3 {
+			return errors.New("disk full")
+		}
+		return os.Rename(from, to)
+	}
+	defer func() { renameFile = os.Rename }()
+
+	changes := testVersionChanges(t, dir, []string{"a.go", "b.go", "c.go"}, "0.2.0")
+	updates, err := prepareFileUpdates(dir, changes)
+	assert.Nil(t, err)
+
+	err = writeFileUpdates(updates)
+	assert.EqualError(t, err, "disk full, no files have been changed")
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		assert.Equal(t, "const VERSION = \"0.1.0\"\n", readTestFile(t, dir, name))
+	}
+
+	// the temp files are cleaned up
+	entries, _ := os.ReadDir(dir)
+	assert.Equal(t, 3, len(entries))
+}
+
+func TestWriteFileUpdatesTempFileFails(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.go": "const VERSION = \"0.1.0\"\n"})
+
+	changes := testVersionChanges(t, dir, []string{"a.go"}, "0.2.0")
+	updates, err := prepareFileUpdates(dir, changes)
+	assert.Nil(t, err)
+	updates = append(updates, &fileUpdate{path: filepath.Join(dir, "missing", "b.go"), content: []byte("")})
+
+	err = writeFileUpdates(updates)
+	assert.NotNil(t, err)
+	assert.Equal(t, "const VERSION = \"0.1.0\"\n", readTestFile(t, dir, "a.go"))
+
+	entries, _ := os.ReadDir(dir)
+	assert.Equal(t, 1, len(entries))
+}
+
+func TestWriteFileUpdatesPreservesFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"lf", "a\nversion = \"0.1.0\"\nb\n", "a\nversion = \"0.2.0\"\nb\n"},
+		{"crlf", "a\r\nversion = \"0.1.0\"\r\nb\r\n", "a\r\nversion = \"0.2.0\"\r\nb\r\n"},
+		{"mixed", "a\r\nversion = \"0.1.0\"\nb\r\n", "a\r\nversion = \"0.2.0\"\nb\r\n"},
+		{"no trailing newline", "a\nversion = \"0.1.0\"", "a\nversion = \"0.2.0\""},
+		{"bom", UTF8_BOM + "version = \"0.1.0\"\r\n", UTF8_BOM + "version = \"0.2.0\"\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{"VERSION": tt.content})
+
+			changes := testVersionChanges(t, dir, []string{"VERSION"}, "0.2.0")
+			assert.Equal(t, 1, len(changes))
+			updates, err := prepareFileUpdates(dir, changes)
+			assert.Nil(t, err)
+			assert.Nil(t, writeFileUpdates(updates))
+			assert.Equal(t, tt.expected, readTestFile(t, dir, "VERSION"))
+		})
+	}
+}
+
+func TestWriteFileUpdatesThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"target.go": "const VERSION = \"0.1.0\"\n"})
+	err := os.Symlink("target.go", filepath.Join(dir, "link.go"))
+	assert.Nil(t, err)
+
+	changes := testVersionChanges(t, dir, []string{"link.go", "target.go"}, "0.2.0")
+	updates, err := prepareFileUpdates(dir, changes)
+	assert.Nil(t, err)
+	// both entries are the same file
+	assert.Equal(t, 1, len(updates))
+	assert.Nil(t, writeFileUpdates(updates))
+
+	info, err := os.Lstat(filepath.Join(dir, "link.go"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink)
+	assert.Equal(t, "const VERSION = \"0.2.0\"\n", readTestFile(t, dir, "target.go"))
+}
+
+func TestUpdateOnlyTheMatchedSpan(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"comment", "version = \"1.2.0\"  # requires go 1.18.1\n", "version = \"1.3.0\"  # requires go 1.18.1\n"},
+		{"badge", "![version: 1.2.0](https://img.shields.io/badge/go-1.18.1-blue)", "![version: 1.3.0](https://img.shields.io/badge/go-1.18.1-blue)"},
+		{"quotes", "VERSION = '1.2.0'; OTHER = '1.2.0'", "VERSION = '1.3.0'; OTHER = '1.2.0'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{"VERSION": tt.content})
+
+			changes := testVersionChanges(t, dir, []string{"VERSION"}, "1.3.0")
+			updates, err := prepareFileUpdates(dir, changes)
+			assert.Nil(t, err)
+			assert.Nil(t, writeFileUpdates(updates))
+			assert.Equal(t, tt.expected, readTestFile(t, dir, "VERSION"))
+		})
+	}
+}
+
+func TestUpdateVersionLineChangedFile(t *testing.T) {
+	v, _ := parseVersion("1.2.0")
+	match := newVersionMatch("main.go", 1, 11, "1.2.0", v)
+
+	lines := []string{"VERSION = \"1.2.0\""}
+	assert.Nil(t, updateVersionLine(lines, match, "1.3.0"))
+	assert.Equal(t, "VERSION = \"1.3.0\"", lines[0])
+
+	lines = []string{"VERSION = \"1.2\""}
+	assert.EqualError(t, updateVersionLine(lines, match, "1.3.0"), "main.go:1 no longer has the version 1.2.0")
+}
+
+func TestUpdateWithSearchAndReplace(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"README.md":   "Install with:\n\n    pip install dover==0.3.0\n",
+		"app.csproj":  "<Project>\n  <Version>0.3.0</Version>\n</Project>\n",
+		"compose.yml": "services:\n  app:\n    image: registry/app:v0.3.0\n",
+	})
+
+	changes := testFileVersionChanges(t, dir, []versionedFile{
+		{path: "README.md", search: `pip install dover=={version}`},
+		{path: "app.csproj", search: `<Version>{version}</Version>`},
+		{path: "compose.yml", search: `image: registry/app:v{version}`, replace: `image: registry/app:{version}`},
+	}, "0.4.0")
+	assert.Equal(t, 3, len(changes))
+
+	updates, err := prepareFileUpdates(dir, changes)
+	assert.Nil(t, err)
+	assert.Nil(t, writeFileUpdates(updates))
+	assert.Equal(t, "Install with:\n\n    pip install dover==0.4.0\n", readTestFile(t, dir, "README.md"))
+	assert.Equal(t, "<Project>\n  <Version>0.4.0</Version>\n</Project>\n", readTestFile(t, dir, "app.csproj"))
+	assert.Equal(t, "services:\n  app:\n    image: registry/app:0.4.0\n", readTestFile(t, dir, "compose.yml"))
+}
+
+func TestUpdateKeyPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"package.json":   PACKAGE_JSON,
+		"pyproject.toml": PYPROJECT_TOML,
+		"Chart.yaml":     CHART_YAML,
+	})
+
+	changes := testVersionChanges(t, dir, []string{"package.json#version", "pyproject.toml#project.version", "Chart.yaml#appVersion"}, "0.4.0")
+	assert.Equal(t, 3, len(changes))
+
+	updates, err := prepareFileUpdates(dir, changes)
+	assert.Nil(t, err)
+	assert.Nil(t, writeFileUpdates(updates))
+	assert.Equal(t, strings.Replace(PACKAGE_JSON, `"version":"0.3.0"`, `"version":"0.4.0"`, 1), readTestFile(t, dir, "package.json"))
+	assert.Equal(t, strings.Replace(PYPROJECT_TOML, `'0.3.0'`, `'0.4.0'`, 1), readTestFile(t, dir, "pyproject.toml"))
+	assert.Equal(t, strings.Replace(CHART_YAML, `"0.3.0"`, `"0.4.0"`, 1), readTestFile(t, dir, "Chart.yaml"))
+}
@@ -1,26 +1,10 @@
 package app
 
 import (
-	"fmt"
 	"os"
-	"strings"
-
-	c "github.com/fatih/color"
+	"path/filepath"
 )
 
-func check(e any) {
-	if e != nil {
-		panic(e)
-	}
-}
-
-func ExitOnError(e error) {
-	if e != nil {
-		fmt.Printf("%s\n", c.RedString(fmt.Sprintf("%s", e)))
-		os.Exit(1)
-	}
-}
-
 func setMax(currentValue int, maxValue *int) {
 	if currentValue > *maxValue {
 		*maxValue = currentValue
@@ -38,29 +22,13 @@ func maxLength(items []string) int {
 	return maxLen
 }
 
-func digitCount(number int) int {
-	if number < 10 {
-		return 1
-	}
-	if number < 100 {
-		return 2
-	}
-	if number < 1000 {
-		return 3
-	}
-	if number < 10000 {
-		return 4
-	}
-	return 5
-}
-
 func getMaxColumnWidths(matches *[]*VersionMatch, format string) (int, int, int) {
 	fileWidth := 0
 	lineWidth := 0
 	versionWidth := 0
 	for _, m := range *matches {
 		setMax(len(m.file), &fileWidth)
-		setMax(digitCount(m.line), &lineWidth)
+		setMax(len(m.position()), &lineWidth)
 		setMax(len(m.version.format(format)), &versionWidth)
 	}
 
@@ -85,10 +53,11 @@ func fileExists(filename string) bool {
 	return !info.IsDir()
 }
 
-func splitFileAndLineNotation(filePath string) (string, string) {
-	parts := strings.Split(filePath, ":")
-	if len(parts) == 1 {
-		return filePath, ""
+// projectPath is where a path from the config points to: relative paths are
+// relative to the project dir, absolute paths are left as they are.
+func projectPath(dir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
 	}
-	return parts[0], parts[1]
+	return filepath.Join(dir, path)
 }
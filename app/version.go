@@ -4,41 +4,31 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"regexp/syntax"
 	"strconv"
+	"strings"
 )
 
-// TODO: combines these strings
+// JUST_VERSION is deliberately loose: it grabs anything that could be a
+// version and leaves it to parseVersionPrefix to decide how much of it is.
 const (
-	JUST_VERSION  = `(?P<major>\d+)(\.(?P<minor>\d+))(\.(?P<patch>\d+))?([\.\-\+](?P<release>[a-z]+)([\.-]?(?P<build>\d+))?)?`
-	VERSION_REGEX = `(version|VERSION|Version)[^ :=]* ?[:=]? ? ["']?(?P<major>\d+)(\.(?P<minor>\d+))(\.(?P<patch>\d+))?([\.\-\+](?P<release>[a-z]+)([\.-]?(?P<build>\d+))?)?["']?`
+	VERSION_SUFFIX = `([\.\-\+]?[0-9A-Za-z]([0-9A-Za-z\.\-\+]*[0-9A-Za-z])?)?`
+	JUST_VERSION   = `\d+\.\d+(\.\d+)?` + VERSION_SUFFIX
+	VERSION_PREFIX = `(version|VERSION|Version)[^ :=]* ?[:=]? ? ["']?`
 )
 
 var (
-	RELEASE  = []string{"dev", "alpha", "beta", "rc"}
-	RELEASES = map[string]string{"dev": "d", "alpha": "a", "beta": "b", "rc": "rc", "d": "dev", "a": "alpha", "b": "beta"}
-	SHORT    = map[string]string{"dev": "d", "alpha": "a", "beta": "b", "rc": "rc", "d": "d", "a": "a", "b": "b"}
-	LONG     = map[string]string{"dev": "dev", "alpha": "alpha", "beta": "beta", "rc": "rc", "d": "dev", "a": "alpha", "b": "beta"}
-	PARTS    = [6]string{"major", "minor", "patch", "release", "prod", "build"}
+	PARTS = [6]string{"major", "minor", "patch", "release", "prod", "build"}
 )
 
 type VersionFinder struct {
-	rx regexp.Regexp
-}
-
-func (vf *VersionFinder) parseRegexResults(match []string) []string {
-	return []string{
-		match[2],  // major
-		match[4],  // minor
-		match[6],  // patch
-		match[8],  // release
-		match[10], // build
-	}
+	rx     regexp.Regexp
+	scheme versionScheme
 }
 
 func (vf *VersionFinder) Find(line string) (Version, bool) {
-	match := vf.rx.FindStringSubmatch(line)
-	if match != nil {
-		return *NewVersion(vf.parseRegexResults(match)), true
+	if found := vf.find(line); found != nil {
+		return found.version, true
 	}
 	return Version{
 		major:   "",
@@ -49,158 +39,380 @@ func (vf *VersionFinder) Find(line string) (Version, bool) {
 	}, false
 }
 
-func NewVersionFinder() *VersionFinder {
-	_rx, _ := regexp.Compile(VERSION_REGEX)
+// finding is a version found in a line. start and end are the byte offsets
+// of the version, matchStart and matchEnd those of all the text the finder's
+// pattern matched.
+type finding struct {
+	version    Version
+	start      int
+	end        int
+	matchStart int
+	matchEnd   int
+}
+
+// find returns nil when the line has no version.
+func (vf *VersionFinder) find(line string) *finding {
+	match := vf.rx.FindStringSubmatchIndex(line)
+	if match == nil {
+		return nil
+	}
+	return vf.finding(line, match)
+}
+
+// findAll returns every version in the line, in order.
+func (vf *VersionFinder) findAll(line string) []*finding {
+	findings := []*finding{}
+	for _, match := range vf.rx.FindAllStringSubmatchIndex(line, -1) {
+		if found := vf.finding(line, match); found != nil {
+			findings = append(findings, found)
+		}
+	}
+	return findings
+}
+
+func (vf *VersionFinder) finding(line string, match []int) *finding {
+	group := vf.rx.SubexpIndex("version")
+	start, end := match[2*group], match[2*group+1]
+	if start < 0 {
+		return nil
+	}
+	v, length, err := vf.scheme.parse(line[start:end])
+	if err != nil {
+		return nil
+	}
+	matchEnd := match[1]
+	if matchEnd == end {
+		// the version pattern is loose, the match ends where the version does
+		matchEnd = start + length
+	}
+	return &finding{version: *v, start: start, end: start + length, matchStart: match[0], matchEnd: matchEnd}
+}
+
+func NewVersionFinder(scheme versionScheme) *VersionFinder {
+	_rx, _ := regexp.Compile(VERSION_PREFIX + `(?P<version>` + scheme.pattern() + `)`)
 	vf := VersionFinder{
-		rx: *_rx,
+		rx:     *_rx,
+		scheme: scheme,
 	}
 	return &vf
 }
 
-func nextRelease(currentRelease string) string {
-	index := IndexOf(&RELEASE, currentRelease)
-	index += 1
-	if index+1 > len(RELEASE) {
-		return ""
+// newMarkerFinder finds a version anywhere in a line, for the lines that a
+// `dover:version` marker says have one.
+func newMarkerFinder(scheme versionScheme) *VersionFinder {
+	rx := regexp.MustCompile(`(?P<version>` + scheme.pattern() + `)`)
+	return &VersionFinder{rx: *rx, scheme: scheme}
+}
+
+// VERSION_PLACEHOLDER marks where the version goes in a versioned file's
+// search and replace templates.
+const VERSION_PLACEHOLDER = "{version}"
+
+// VERSION_GROUP starts the group of a search regex that holds the version,
+// for searches that spell out the version they are after instead of using
+// the {version} placeholder, e.g. `version=(?P<version>0\.3\.0)`.
+const VERSION_GROUP = `(?P<version>`
+
+// newSearchFinder finds versions with a versioned file's own search regex,
+// e.g. `pip install dover=={version}`.
+func newSearchFinder(search string, scheme versionScheme) (*VersionFinder, error) {
+	pattern := search
+	switch {
+	case strings.Count(search, VERSION_GROUP) == 1 && !strings.Contains(search, VERSION_PLACEHOLDER):
+	case strings.Count(search, VERSION_PLACEHOLDER) == 1 && !strings.Contains(search, VERSION_GROUP):
+		pattern = strings.Replace(search, VERSION_PLACEHOLDER, VERSION_GROUP+scheme.pattern()+`)`, 1)
+	default:
+		return nil, fmt.Errorf("search pattern `%s` must contain %s once", search, VERSION_PLACEHOLDER)
+	}
+	rx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern `%s`: %s", search, err)
 	}
-	return RELEASE[index]
+	tree, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern `%s`: %s", search, err)
+	}
+	if optionalVersionGroup(tree, false) {
+		return nil, fmt.Errorf("search pattern `%s` can match without a version, %s can not be optional", search, VERSION_PLACEHOLDER)
+	}
+	return &VersionFinder{rx: *rx, scheme: scheme}, nil
 }
 
-func validateReleaseOrder(currentRelease string, requestedRelease string) error {
-	currentIndex := IndexOf(&RELEASE, currentRelease)
-	requestedIndex := IndexOf(&RELEASE, requestedRelease)
-	if requestedIndex < currentIndex {
-		msg := fmt.Sprintf("Invalid release order requested. `%s` comes before the current release `%s`.", requestedRelease, currentRelease)
-		return errors.New(msg)
+// optionalVersionGroup tells if a match of the regex can leave out the
+// version group: the group is inside a `?`, a `*`, a `{0,n}` or one side of
+// an `|`.
+func optionalVersionGroup(re *syntax.Regexp, optional bool) bool {
+	switch re.Op {
+	case syntax.OpCapture:
+		if re.Name == "version" {
+			return optional
+		}
+	case syntax.OpQuest, syntax.OpStar, syntax.OpAlternate:
+		optional = true
+	case syntax.OpRepeat:
+		optional = optional || re.Min == 0
 	}
-	return nil
+	for _, sub := range re.Sub {
+		if optionalVersionGroup(sub, optional) {
+			return true
+		}
+	}
+	return false
 }
 
+// Version holds a parsed version number. The release and build fields are
+// dover's view of the pre-release (e.g. `beta.2`); any further dot-separated
+// pre-release identifiers are kept in identifiers and the `+build` metadata in
+// metadata, so that strings such as `1.4.0-beta.2.hotfix+sha.5114f85` survive
+// a round trip.
+//
+// The epoch, segments, post and dev fields are only used by schemes that
+// have them (e.g. PEP 440, where metadata holds the local version). A nil
+// scheme means SemVer.
 type Version struct {
-	major   string
-	minor   string
-	patch   string
-	release string
-	build   string
+	epoch       string
+	major       string
+	minor       string
+	patch       string
+	segments    []string
+	release     string
+	build       string
+	identifiers []string
+	post        string
+	dev         string
+	metadata    []string
+	scheme      versionScheme
 }
 
 func (v *Version) copy() Version {
 	nv := Version{
-		major:   v.major,
-		minor:   v.minor,
-		patch:   v.patch,
-		release: v.release,
-		build:   v.build,
+		epoch:       v.epoch,
+		major:       v.major,
+		minor:       v.minor,
+		patch:       v.patch,
+		segments:    append([]string(nil), v.segments...),
+		release:     v.release,
+		build:       v.build,
+		identifiers: append([]string(nil), v.identifiers...),
+		post:        v.post,
+		dev:         v.dev,
+		metadata:    append([]string(nil), v.metadata...),
+		scheme:      v.scheme,
 	}
 	return nv
 }
 
+func (v *Version) getScheme() versionScheme {
+	if v.scheme == nil {
+		return semverScheme{}
+	}
+	return v.scheme
+}
+
+func (v *Version) releases() *releaseLadder {
+	return v.getScheme().releases()
+}
+
+// format writes the version using a format string that has already been
+// validated, see NewVersionFormater.
 func (v *Version) format(fmtString string) string {
-	f := NewVersionFormater(fmtString)
-	return f.format(v)
+	f, err := NewVersionFormater(fmtString)
+	if err != nil {
+		f, _ = NewVersionFormater(CANONICAL_FORMAT)
+	}
+	return v.getScheme().format(v, f)
 }
 
 func (v *Version) toString() string {
-	return v.format("000-A.0")
+	return v.format(CANONICAL_FORMAT)
 }
 
-func (v *Version) bumpMajor() Version {
-	major, err := strconv.Atoi(v.major)
-	check(err)
+// Format writes the version using a dover format string (e.g. `000-A.0`).
+func (v *Version) Format(fmtString string) (string, error) {
+	if _, err := NewVersionFormater(fmtString); err != nil {
+		return "", err
+	}
+	return v.format(fmtString), nil
+}
+
+func (v *Version) String() string {
+	return v.toString()
+}
+
+// Major is the major version number.
+func (v *Version) Major() string {
+	return v.major
+}
 
-	major = major + 1
+// Minor is the minor version number.
+func (v *Version) Minor() string {
+	return v.minor
+}
+
+// Patch is the patch version number, empty for a version written without
+// one.
+func (v *Version) Patch() string {
+	return v.patch
+}
+
+// PreRelease is the name of the pre-release (e.g. `alpha`), empty for a
+// release.
+func (v *Version) PreRelease() string {
+	return v.release
+}
+
+// Build is the pre-release number (the `2` of `beta.2`), empty when the
+// pre-release has none.
+func (v *Version) Build() string {
+	if v.release == "" {
+		return ""
+	}
+	return v.build
+}
+
+// Identifiers are the pre-release identifiers that follow the pre-release
+// name and number (the `hotfix` of `1.4.0-beta.2.hotfix`).
+func (v *Version) Identifiers() []string {
+	return append([]string(nil), v.identifiers...)
+}
+
+// Metadata are the build metadata identifiers (the `sha.5114f85` of
+// `1.0.0+sha.5114f85`), or the local version of a PEP 440 version.
+func (v *Version) Metadata() []string {
+	return append([]string(nil), v.metadata...)
+}
+
+// Compare orders the version against another of the same scheme, returning
+// -1, 0 or 1. Build metadata does not take part.
+func (v *Version) Compare(other *Version) int {
+	return v.compare(other)
+}
+
+func (v *Version) hasPreRelease() bool {
+	return v.release != "" || len(v.identifiers) > 0
+}
+
+// releaseSegments returns the numeric release segments, leaving out a patch
+// number the version was written without.
+func (v *Version) releaseSegments() []string {
+	segments := []string{v.major, v.minor}
+	if v.patch != "" {
+		segments = append(segments, v.patch)
+	}
+	return append(segments, v.segments...)
+}
+
+// preReleaseIdentifiers returns the full list of pre-release identifiers
+// with the release name in its long form.
+func (v *Version) preReleaseIdentifiers() []string {
+	ids := []string{}
+	if v.release != "" {
+		ids = append(ids, v.releases().longName(v.release))
+		if v.build != "" {
+			ids = append(ids, v.build)
+		}
+	}
+	return append(ids, v.identifiers...)
+}
+
+// setPreReleaseIdentifiers splits SemVer pre-release identifiers into the
+// release name, the build number and whatever identifiers remain.
+func (v *Version) setPreReleaseIdentifiers(ids []string) {
+	if match := labelBuildRegex.FindStringSubmatch(ids[0]); match != nil {
+		// dover's own compact forms: a0, rc1, d-1
+		v.release, v.build, ids = match[1], match[2], ids[1:]
+	} else if !isNumeric(ids[0]) {
+		// a label without a number keeps no build, `alpha` comes before `alpha.0`
+		v.release, v.build, ids = ids[0], "", ids[1:]
+		if len(ids) > 0 && isNumeric(ids[0]) {
+			v.build, ids = ids[0], ids[1:]
+		}
+	}
+	if len(ids) > 0 {
+		v.identifiers = ids
+	}
+}
+
+func (v *Version) bumpMajor() Version {
 	nv := Version{
-		major:   strconv.Itoa(major),
+		major:   incrementNumber(v.major),
 		minor:   "0",
 		patch:   "0",
 		release: "",
 		build:   "0",
+		scheme:  v.scheme,
 	}
 	return nv
 }
 
 func (v *Version) bumpMinor() Version {
-	minor, err := strconv.Atoi(v.minor)
-	check(err)
-
-	minor = minor + 1
 	nv := Version{
 		major:   v.major,
-		minor:   strconv.Itoa(minor),
+		minor:   incrementNumber(v.minor),
 		patch:   "0",
 		release: "",
 		build:   "0",
+		scheme:  v.scheme,
 	}
 	return nv
 }
 
 func (v *Version) bumpPatch() Version {
-	patch, err := strconv.Atoi(v.patch)
-	check(err)
-
-	patch = patch + 1
 	nv := Version{
 		major:   v.major,
 		minor:   v.minor,
-		patch:   strconv.Itoa(patch),
+		patch:   incrementNumber(v.patch),
 		release: "",
 		build:   "0",
+		scheme:  v.scheme,
 	}
 	return nv
 }
 
-func (v *Version) setPreRelease(release string) Version {
-	err := validateReleaseOrder(v.release, release)
-	ExitOnError(err)
+func (v *Version) setPreRelease(release string) (Version, error) {
+	err := v.releases().validateReleaseOrder(v.release, release)
+	if err != nil {
+		return Version{}, err
+	}
 
+	release = v.releases().longName(release)
 	nv := v.copy()
-	if nv.release != release {
+	if v.releases().longName(nv.release) != release {
 		nv.release = release
 		nv.build = "0"
+		nv.identifiers = nil
 	}
-	return nv
+	return nv, nil
 }
 
 func (v *Version) bumpRelease() Version {
-	release := nextRelease(v.release)
-
-	nv := Version{
-		major:   v.major,
-		minor:   v.minor,
-		patch:   v.patch,
-		release: release,
-		build:   "0",
-	}
+	nv := v.copy()
+	nv.release = v.releases().nextRelease(v.release)
+	nv.build = "0"
+	nv.identifiers = nil
 	return nv
 }
 
 func (v *Version) bumpReleaseToProd() Version {
-	nv := Version{
-		major:   v.major,
-		minor:   v.minor,
-		patch:   v.patch,
-		release: "",
-		build:   "0",
-	}
+	nv := v.copy()
+	nv.release = ""
+	nv.build = "0"
+	nv.identifiers = nil
 	return nv
 }
 
 func (v *Version) bumpBuild() Version {
-	build, err := strconv.Atoi(v.build)
-	check(err)
-
-	build = build + 1
-	nv := Version{
-		major:   v.major,
-		minor:   v.minor,
-		patch:   v.patch,
-		release: v.release,
-		build:   strconv.Itoa(build),
-	}
+	nv := v.copy()
+	nv.build = incrementNumber(v.build)
+	nv.identifiers = nil
 	return nv
 }
 
-func (v *Version) bump(part string, preRelease string) Version {
+func (v *Version) bump(part string, preRelease string) (Version, error) {
+	return v.getScheme().bump(v, part, preRelease)
+}
+
+func (v *Version) bumpSemVer(part string, preRelease string) (Version, error) {
 	newVers := v.copy()
 
 	switch part {
@@ -210,32 +422,174 @@ func (v *Version) bump(part string, preRelease string) Version {
 		newVers = newVers.bumpMinor()
 	case "patch":
 		newVers = newVers.bumpPatch()
+	case "calver":
+		return Version{}, &UsageError{Err: errors.New("--calver is only supported by the calver version scheme")}
 	}
 
+	var err error
 	switch preRelease {
+	case "":
 	case "pre-release":
 		newVers = newVers.bumpRelease()
-	case "dev":
-		newVers = newVers.setPreRelease(preRelease)
-	case "alpha":
-		newVers = newVers.setPreRelease(preRelease)
-	case "beta":
-		newVers = newVers.setPreRelease(preRelease)
-	case "rc":
-		newVers = newVers.setPreRelease(preRelease)
 	case "release":
 		newVers = newVers.bumpReleaseToProd()
+	case "post":
+		return Version{}, &UsageError{Err: errors.New("post releases are only supported by the pep440 version scheme")}
+	default:
+		newVers, err = newVers.setPreRelease(preRelease)
+		if err != nil {
+			return Version{}, err
+		}
 	}
 
-	if newVers.release != "" && (part == "build" || v.release == preRelease) {
+	ladder := v.releases()
+	if newVers.release != "" && (part == "build" || ladder.longName(v.release) == ladder.longName(preRelease)) {
 		newVers = newVers.bumpBuild()
 	}
 
-	return newVers
+	// build metadata describes the build it came from, never the next one
+	newVers.metadata = nil
+
+	return newVers, nil
+}
+
+// compare orders two versions of the same scheme and returns -1, 0 or 1.
+func (v *Version) compare(other *Version) int {
+	return v.getScheme().compare(v, other)
+}
+
+// compareSemVer orders two versions by SemVer 2.0.0 precedence. Build
+// metadata does not take part in precedence.
+func (v *Version) compareSemVer(other *Version) int {
+	for _, pair := range [][2]string{{v.major, other.major}, {v.minor, other.minor}, {v.patch, other.patch}} {
+		if result := compareNumeric(pair[0], pair[1]); result != 0 {
+			return result
+		}
+	}
+	return comparePreRelease(v.preReleaseIdentifiers(), other.preReleaseIdentifiers())
+}
+
+// checkBumpOrder refuses a bump that does not move the version forward. Only
+// a new pre-release of a final version may sort before it; --pre-release on
+// 2.0.0-x.7 would go back to 2.0.0-dev.0.
+func checkBumpOrder(v *Version, next *Version) error {
+	if (v.hasPreRelease() || !next.hasPreRelease()) && next.compareLadder(v) <= 0 {
+		return &ReleaseOrderError{Current: v.toString(), Requested: next.toString()}
+	}
+	return nil
+}
+
+// compareLadder orders two versions the way dover bumps them: pre-releases
+// on the release ladder go by their place on it, below any pre-release that
+// is not on it, and otherwise by precedence.
+func (v *Version) compareLadder(other *Version) int {
+	final, otherFinal := v.copy(), other.copy()
+	for _, f := range []*Version{&final, &otherFinal} {
+		f.release, f.build, f.identifiers = "", "", nil
+	}
+	if result := final.compare(&otherFinal); result != 0 {
+		return result
+	}
+	return comparePreRelease(v.ladderIdentifiers(), other.ladderIdentifiers())
+}
+
+// ladderIdentifiers are the pre-release identifiers with a release on the
+// ladder swapped for its place on it, so that dev sorts before alpha.
+func (v *Version) ladderIdentifiers() []string {
+	ids := v.preReleaseIdentifiers()
+	if index := v.releases().index(v.release); v.release != "" && index != -1 {
+		ids[0] = strconv.Itoa(index)
+	}
+	return ids
 }
 
 func (v *Version) equals(other *Version) bool {
-	return v.toString() == other.toString()
+	return v.compare(other) == 0
+}
+
+// identical is equals with the build metadata too, for versioned files that
+// have to agree on the version they hold and not only on its precedence.
+func (v *Version) identical(other *Version) bool {
+	return v.equals(other) && strings.Join(v.metadata, ".") == strings.Join(other.metadata, ".")
+}
+
+func comparePreRelease(ids []string, otherIds []string) int {
+	// a version without a pre-release has the higher precedence
+	if len(ids) == 0 || len(otherIds) == 0 {
+		return compareInt(len(otherIds), len(ids))
+	}
+	for index := 0; index < len(ids) && index < len(otherIds); index++ {
+		if result := compareIdentifier(ids[index], otherIds[index]); result != 0 {
+			return result
+		}
+	}
+	return compareInt(len(ids), len(otherIds))
+}
+
+func compareIdentifier(id string, otherId string) int {
+	idNumeric, otherNumeric := isNumeric(id), isNumeric(otherId)
+	switch {
+	case idNumeric && otherNumeric:
+		return compareNumeric(id, otherId)
+	case idNumeric:
+		return -1
+	case otherNumeric:
+		return 1
+	}
+	return strings.Compare(id, otherId)
+}
+
+func compareNumeric(number string, otherNumber string) int {
+	number = strings.TrimLeft(number, "0")
+	otherNumber = strings.TrimLeft(otherNumber, "0")
+	if len(number) != len(otherNumber) {
+		return compareInt(len(number), len(otherNumber))
+	}
+	return strings.Compare(number, otherNumber)
+}
+
+func compareInt(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isNumeric(input string) bool {
+	if input == "" {
+		return false
+	}
+	for _, r := range input {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// incrementNumber adds one to a number kept as a string, which unlike
+// strconv cannot overflow.
+func incrementNumber(number string) string {
+	digits := []byte(normalizeNumber(number))
+	for index := len(digits) - 1; index >= 0; index-- {
+		if digits[index] < '9' {
+			digits[index]++
+			return string(digits)
+		}
+		digits[index] = '0'
+	}
+	return "1" + string(digits)
+}
+
+func normalizeNumber(number string) string {
+	number = strings.TrimLeft(number, "0")
+	if number == "" {
+		return "0"
+	}
+	return number
 }
 
 func defaultZeroStr(input string) string {
@@ -255,3 +609,85 @@ func NewVersion(match []string) *Version {
 	}
 	return &v
 }
+
+var (
+	coreRegex       = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+	legacyRegex     = regexp.MustCompile(`^[\.\+]?([A-Za-z]+)([\.-]?(\d+))?`)
+	identifierRegex = regexp.MustCompile(`^[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*`)
+	labelBuildRegex = regexp.MustCompile(`^([A-Za-z]+)-?(\d+)$`)
+)
+
+// parseVersionPrefix parses the version at the start of text and returns it
+// together with the number of bytes it took up.
+//
+// Besides SemVer 2.0.0 it accepts every style dover's own formats produce,
+// i.e. `0.4.0.dev.1`, `0.4d1` or `0.4.0+dev.1`. A `+` is only read as a
+// release separator when it is followed by a known release name, otherwise
+// it starts the build metadata.
+func parseVersionPrefix(text string, scheme versionScheme) (*Version, int, error) {
+	core := coreRegex.FindStringSubmatch(text)
+	if core == nil {
+		return nil, 0, &ParseError{Kind: "version", Text: text}
+	}
+	v := NewVersion([]string{core[1], core[2], core[3], "", ""})
+	v.scheme = scheme
+	pos := len(core[0])
+	pos += parseVersionSuffix(v, text[pos:])
+
+	return v, pos, nil
+}
+
+// parseVersionSuffix reads the pre-release and build metadata that follow
+// the numeric part of a version into v and returns the number of bytes used.
+func parseVersionSuffix(v *Version, text string) int {
+	pos := 0
+
+	if strings.HasPrefix(text[pos:], "-") {
+		if ids := identifierRegex.FindString(text[pos+1:]); ids != "" {
+			v.setPreReleaseIdentifiers(strings.Split(ids, "."))
+			pos += 1 + len(ids)
+		}
+	} else if legacy := legacyRegex.FindStringSubmatch(text[pos:]); legacy != nil {
+		knownRelease := v.releases().has(legacy[1])
+		if !strings.HasPrefix(text[pos:], "+") || knownRelease {
+			v.release = legacy[1]
+			v.build = defaultZeroStr(legacy[3])
+			pos += len(legacy[0])
+			if strings.HasPrefix(text[pos:], ".") {
+				if ids := identifierRegex.FindString(text[pos+1:]); ids != "" {
+					if legacy[3] == "" {
+						v.build = ""
+					}
+					v.identifiers = strings.Split(ids, ".")
+					pos += 1 + len(ids)
+				}
+			}
+		}
+	}
+
+	if strings.HasPrefix(text[pos:], "+") {
+		if ids := identifierRegex.FindString(text[pos+1:]); ids != "" {
+			v.metadata = strings.Split(ids, ".")
+			pos += 1 + len(ids)
+		}
+	}
+
+	return pos
+}
+
+// parseVersion parses a complete version string, such as a git tag or a
+// version given on the command line. A leading `v` is allowed.
+func parseVersion(text string) (*Version, error) {
+	trimmed := strings.TrimPrefix(text, "v")
+	v, length, err := parseVersionPrefix(trimmed, semverScheme{})
+	if err != nil || length != len(trimmed) {
+		return nil, &ParseError{Kind: "version", Text: text}
+	}
+	numbers := append([]string{v.major, v.minor, v.patch}, v.preReleaseIdentifiers()...)
+	for _, number := range numbers {
+		if isNumeric(number) && len(number) > 1 && strings.HasPrefix(number, "0") {
+			return nil, &ParseError{Kind: "version", Text: text, Reason: "has a leading zero"}
+		}
+	}
+	return v, nil
+}
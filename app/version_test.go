@@ -1,6 +1,7 @@
 package app
 
 import "fmt"
+import "regexp"
 import "testing"
 import "github.com/stretchr/testify/assert"
 
@@ -79,73 +80,74 @@ func TestVersionBumpRelease(t *testing.T) {
 
 func TestValidateReleaseOrder(t *testing.T) {
 	// dev
-	err := validateReleaseOrder("", "dev")
+	err := DEFAULT_RELEASES.validateReleaseOrder("", "dev")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("dev", "dev")
+	err = DEFAULT_RELEASES.validateReleaseOrder("dev", "dev")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("alpha", "dev")
+	err = DEFAULT_RELEASES.validateReleaseOrder("alpha", "dev")
 	assert.NotNil(t, err)
 
-	err = validateReleaseOrder("beta", "dev")
+	err = DEFAULT_RELEASES.validateReleaseOrder("beta", "dev")
 	assert.NotNil(t, err)
 
-	err = validateReleaseOrder("rc", "dev")
+	err = DEFAULT_RELEASES.validateReleaseOrder("rc", "dev")
 	assert.NotNil(t, err)
 
 	// alpha
-	err = validateReleaseOrder("", "alpha")
+	err = DEFAULT_RELEASES.validateReleaseOrder("", "alpha")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("dev", "alpha")
+	err = DEFAULT_RELEASES.validateReleaseOrder("dev", "alpha")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("alpha", "alpha")
+	err = DEFAULT_RELEASES.validateReleaseOrder("alpha", "alpha")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("beta", "alpha")
+	err = DEFAULT_RELEASES.validateReleaseOrder("beta", "alpha")
 	assert.NotNil(t, err)
 
-	err = validateReleaseOrder("rc", "alpha")
+	err = DEFAULT_RELEASES.validateReleaseOrder("rc", "alpha")
 	assert.NotNil(t, err)
 
 	// beta
-	err = validateReleaseOrder("", "beta")
+	err = DEFAULT_RELEASES.validateReleaseOrder("", "beta")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("dev", "beta")
+	err = DEFAULT_RELEASES.validateReleaseOrder("dev", "beta")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("alpha", "beta")
+	err = DEFAULT_RELEASES.validateReleaseOrder("alpha", "beta")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("beta", "beta")
+	err = DEFAULT_RELEASES.validateReleaseOrder("beta", "beta")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("rc", "beta")
+	err = DEFAULT_RELEASES.validateReleaseOrder("rc", "beta")
 	assert.NotNil(t, err)
 
 	// rc
-	err = validateReleaseOrder("", "rc")
+	err = DEFAULT_RELEASES.validateReleaseOrder("", "rc")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("dev", "rc")
+	err = DEFAULT_RELEASES.validateReleaseOrder("dev", "rc")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("alpha", "rc")
+	err = DEFAULT_RELEASES.validateReleaseOrder("alpha", "rc")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("beta", "rc")
+	err = DEFAULT_RELEASES.validateReleaseOrder("beta", "rc")
 	assert.Nil(t, err)
 
-	err = validateReleaseOrder("rc", "rc")
+	err = DEFAULT_RELEASES.validateReleaseOrder("rc", "rc")
 	assert.Nil(t, err)
 
 }
 
 func assertNewVersion(t *testing.T, version *Version, part string, preRelease string, equals string) {
-	v2 := version.bump(part, preRelease)
+	v2, err := version.bump(part, preRelease)
+	assert.Nil(t, err)
 	assert.Equal(t, equals, v2.toString())
 
 }
@@ -169,3 +171,234 @@ func TestVersionBump(t *testing.T) {
 	assertNewVersion(t, v1, "", "release", "0.1.2")
 
 }
+
+func TestParseVersionRoundTrip(t *testing.T) {
+	var tests = []struct {
+		input, expected string
+	}{
+		{"1.4.0-beta.2.hotfix+sha.5114f85", "1.4.0-beta.2.hotfix+sha.5114f85"},
+		{"2.0.0-x.7.z.92", "2.0.0-x.7.z.92"},
+		{"1.0.0-alpha.beta", "1.0.0-alpha.beta"},
+		{"1.0.0-0.3.7", "1.0.0-0.3.7"},
+		{"1.0.0+20130313144700", "1.0.0+20130313144700"},
+		{"1.0.0-rc.1+exp.sha.5114f85", "1.0.0-rc.1+exp.sha.5114f85"},
+		{"v1.2.3", "1.2.3"},
+		{"0.4.0+dev.1", "0.4.0-dev.1"},
+		{"0.4.0.dev.1", "0.4.0-dev.1"},
+		{"0.4.0-d-1", "0.4.0-dev.1"},
+		{"0.1.0-a0", "0.1.0-alpha.0"},
+		{"0.1.0-alpha", "0.1.0-alpha"},
+		{"1.0.0-alpha+001", "1.0.0-alpha+001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := parseVersion(tt.input)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, v.toString())
+		})
+	}
+}
+
+func TestParseVersionComponents(t *testing.T) {
+	v, err := parseVersion("1.4.0-beta.2.hotfix+sha.5114f85")
+	assert.Nil(t, err)
+	assertVersion(t, v, "1", "4", "0", "beta", "2")
+	assert.Equal(t, []string{"hotfix"}, v.identifiers)
+	assert.Equal(t, []string{"sha", "5114f85"}, v.metadata)
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	for _, input := range []string{"", "1", "a.b.c", "01.2.3", "1.2.3-01", "1.2.3-", "1.2.3+", "1.2.3-beta..1"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := parseVersion(input)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestVersionPrecedence(t *testing.T) {
+	// the ordering example given by the SemVer 2.0.0 specification
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+		"10.0.0",
+	}
+
+	for index := 1; index < len(ordered); index++ {
+		lower, _ := parseVersion(ordered[index-1])
+		higher, _ := parseVersion(ordered[index])
+		assert.Equal(t, -1, lower.compare(higher), fmt.Sprintf("%s < %s", ordered[index-1], ordered[index]))
+		assert.Equal(t, 1, higher.compare(lower), fmt.Sprintf("%s > %s", ordered[index], ordered[index-1]))
+	}
+}
+
+func TestVersionPrecedenceOfImplicitBuild(t *testing.T) {
+	// a pre-release without a number is not the same as its build 0
+	alpha, _ := parseVersion("1.0.0-alpha")
+	alpha0, _ := parseVersion("1.0.0-alpha.0")
+	assert.Equal(t, -1, alpha.compare(alpha0))
+	assert.False(t, alpha.equals(alpha0))
+
+	assertNewVersion(t, alpha, "build", "", "1.0.0-alpha.1")
+	assertNewVersion(t, alpha, "", "alpha", "1.0.0-alpha.1")
+}
+
+func TestVersionEqualsIgnoresMetadata(t *testing.T) {
+	v1, _ := parseVersion("1.0.0-a0+build.1")
+	v2, _ := parseVersion("1.0.0-alpha.0+build.2")
+	assert.True(t, v1.equals(v2))
+	assert.False(t, v1.identical(v2))
+
+	v3, _ := parseVersion("1.0.0-alpha.0+build.1")
+	assert.True(t, v1.identical(v3))
+}
+
+func TestVersionBumpMovesForward(t *testing.T) {
+	var tests = []struct {
+		version, part, preRelease string
+	}{
+		{"2.0.0-x.7.z.92", "", "pre-release"},
+		{"2.0.0-x.7", "", "alpha"},
+		{"1.2.0", "", "release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.preRelease, func(t *testing.T) {
+			v, _ := parseVersion(tt.version)
+			_, err := v.bump(tt.part, tt.preRelease)
+			assert.IsType(t, &ReleaseOrderError{}, err)
+		})
+	}
+
+	// a new pre-release of a final version, and dev on to alpha, are fine
+	v, _ := parseVersion("1.2.0")
+	assertNewVersion(t, v, "", "dev", "1.2.0-dev.0")
+	v, _ = parseVersion("1.2.0-dev.3")
+	assertNewVersion(t, v, "", "alpha", "1.2.0-alpha.0")
+	v, _ = parseVersion("2.0.0-x.7.z.92")
+	assertNewVersion(t, v, "build", "", "2.0.0-x.8")
+}
+
+func TestVersionBumpDropsMetadata(t *testing.T) {
+	v, _ := parseVersion("1.4.0-beta.2.hotfix+sha.5114f85")
+
+	assertNewVersion(t, v, "build", "", "1.4.0-beta.3")
+	assertNewVersion(t, v, "", "beta", "1.4.0-beta.3")
+	assertNewVersion(t, v, "", "rc", "1.4.0-rc.0")
+	assertNewVersion(t, v, "", "release", "1.4.0")
+	assertNewVersion(t, v, "minor", "", "1.5.0")
+}
+
+func TestVersionFinder(t *testing.T) {
+	var tests = []struct {
+		line, expected string
+	}{
+		{`__version__ = "0.1.0-a0"`, "0.1.0-alpha.0"},
+		{`VERSION = "1.4.0-beta.2.hotfix+sha.5114f85"`, "1.4.0-beta.2.hotfix+sha.5114f85"},
+		{`  "version": "2.0.0-x.7.z.92",`, "2.0.0-x.7.z.92"},
+		{`*version 0.3.0*`, "0.3.0"},
+		{`version: 1.2.3.4`, "1.2.3"},
+	}
+
+	finder := NewVersionFinder(semverScheme{})
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			v, found := finder.Find(tt.line)
+			assert.True(t, found)
+			assert.Equal(t, tt.expected, v.toString())
+		})
+	}
+}
+
+func TestVersionFinderSpan(t *testing.T) {
+	var tests = []struct {
+		line       string
+		start, end int
+	}{
+		{`__version__ = "0.1.0-a0"`, 15, 23},
+		{`version = "1.2.0"  # requires go 1.18.1`, 11, 16},
+		{`*version 0.3.0*`, 9, 14},
+	}
+
+	finder := NewVersionFinder(semverScheme{})
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			found := finder.find(tt.line)
+			assert.NotNil(t, found)
+			assert.Equal(t, tt.start, found.start)
+			assert.Equal(t, tt.end, found.end)
+		})
+	}
+}
+
+func TestVersionFinderFindAll(t *testing.T) {
+	finder := NewVersionFinder(semverScheme{})
+	found := finder.findAll(`VERSION = "1.2.0"; version: 1.2.0  # was version 1.1.0`)
+	assert.Equal(t, 3, len(found))
+	assert.Equal(t, []int{11, 28, 49}, []int{found[0].start, found[1].start, found[2].start})
+	assert.Equal(t, "1.1.0", found[2].version.toString())
+
+	assert.Equal(t, 0, len(finder.findAll(`nothing to see`)))
+}
+
+func TestSearchFinder(t *testing.T) {
+	var tests = []struct {
+		search, line, expected string
+	}{
+		{`appVersion: {version}`, `appVersion: 1.2.0`, "1.2.0"},
+		{`pip install dover=={version}`, `    pip install dover==0.3.0`, "0.3.0"},
+		{`ARG APP_TAG={version}`, `ARG APP_TAG=0.3.0-rc.1`, "0.3.0-rc.1"},
+		{`<Version>{version}</Version>`, `  <Version>0.3.0</Version>`, "0.3.0"},
+		{`version=(?P<version>0\.3\.0)`, `version=0.3.0`, "0.3.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			finder, err := newSearchFinder(tt.search, semverScheme{})
+			assert.Nil(t, err)
+			v, found := finder.Find(tt.line)
+			assert.True(t, found)
+			assert.Equal(t, tt.expected, v.toString())
+		})
+	}
+}
+
+func TestInvalidSearchFinder(t *testing.T) {
+	_, err := newSearchFinder(`appVersion: `, semverScheme{})
+	assert.EqualError(t, err, "search pattern `appVersion: ` must contain {version} once")
+
+	_, err = newSearchFinder(`appVersion: ({version}`, semverScheme{})
+	assert.NotNil(t, err)
+
+	_, err = newSearchFinder(`(?P<version>0\.3\.0) {version}`, semverScheme{})
+	assert.NotNil(t, err)
+
+	for _, search := range []string{`tag(={version})?`, `tag(={version})*`, `tag(={version}){0,2}`, `tag={version}|tag`, `version=(?P<version>0\.3\.0)?`} {
+		_, err = newSearchFinder(search, semverScheme{})
+		assert.EqualError(t, err, "search pattern `"+search+"` can match without a version, {version} can not be optional")
+	}
+
+	finder, err := newSearchFinder(`tag(={version})+`, semverScheme{})
+	assert.Nil(t, err)
+	v, found := finder.Find(`tag=1.2.0`)
+	assert.True(t, found)
+	assert.Equal(t, "1.2.0", v.toString())
+}
+
+func TestFindingWithoutTheVersionGroup(t *testing.T) {
+	// a finder built around newSearchFinder's check must still not panic
+	finder := &VersionFinder{rx: *regexp.MustCompile(`tag(=(?P<version>` + semverScheme{}.pattern() + `))?`), scheme: semverScheme{}}
+	_, found := finder.Find(`tag`)
+	assert.False(t, found)
+	assert.Equal(t, 0, len(finder.findAll(`tag tag`)))
+}
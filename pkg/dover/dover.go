@@ -0,0 +1,86 @@
+// Package dover reads and bumps the version of a project configured for
+// dover, for tools that want to embed dover rather than shell out to it.
+//
+//	project, err := dover.LoadProject(".")
+//	if err != nil {
+//		return err
+//	}
+//	plan, err := project.Plan(dover.Bump{Part: dover.Minor})
+//	if err != nil {
+//		return err
+//	}
+//	err = project.Apply(plan)
+package dover
+
+import "github.com/markgemmill/dover/app"
+
+type (
+	Project       = app.Project
+	Version       = app.Version
+	VersionMatch  = app.VersionMatch
+	Bump          = app.Bump
+	Plan          = app.Plan
+	VersionChange = app.VersionChange
+	Sync          = app.Sync
+	AutoBump      = app.AutoBump
+
+	ConventionalCommit = app.ConventionalCommit
+	ChangelogRelease   = app.ChangelogRelease
+	ReleaseNotes       = app.ReleaseNotes
+)
+
+// Errors returned by a Project.
+type (
+	ConfigError              = app.ConfigError
+	ParseError               = app.ParseError
+	InconsistentVersionError = app.InconsistentVersionError
+	NoVersionError           = app.NoVersionError
+	InvalidFormatError       = app.InvalidFormatError
+	ReleaseOrderError        = app.ReleaseOrderError
+	GitError                 = app.GitError
+	UsageError               = app.UsageError
+)
+
+// Bump parts.
+const (
+	Major  = "major"
+	Minor  = "minor"
+	Patch  = "patch"
+	Build  = "build"
+	CalVer = "calver"
+)
+
+// Bump pre-releases. Any name on the project's pre-release ladder (alpha,
+// beta, ...) can also be used.
+const (
+	NextPreRelease = "pre-release"
+	Release        = "release"
+	Post           = "post"
+	Dev            = "dev"
+)
+
+// Sync strategies.
+const (
+	Highest  = app.SYNC_HIGHEST
+	Majority = app.SYNC_MAJORITY
+	Source   = app.SYNC_SOURCE
+)
+
+// LoadProject reads the dover configuration in dir and searches the
+// versioned files for their version strings.
+func LoadProject(dir string) (*Project, error) {
+	return app.LoadProject(dir)
+}
+
+// FindProject is LoadProject for the closest of dir and its parents that has
+// a dover configuration, going no further up than the git repository root.
+func FindProject(dir string) (*Project, error) {
+	return app.FindProject(dir)
+}
+
+// LoadProjectConfig reads the dover configuration in configFile, with the
+// versioned files relative to dir, or to the directory of configFile when
+// dir is empty.
+func LoadProjectConfig(configFile string, dir string) (*Project, error) {
+	return app.LoadProjectConfig(configFile, dir)
+}
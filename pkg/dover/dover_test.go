@@ -0,0 +1,124 @@
+package dover_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markgemmill/dover/pkg/dover"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeProject(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0666)
+		assert.Nil(t, err)
+	}
+	return dir
+}
+
+func readFile(t *testing.T, dir string, name string) string {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	assert.Nil(t, err)
+	return string(content)
+}
+
+func TestPlanAndApply(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		".dover":      "[dover]\nversion_format = \"000-A.0\"\nversioned_files = [\"main.go\", \"version.txt\"]\n",
+		"main.go":     "package main\n\nconst VERSION = \"1.4.0-beta.2+sha.5114f85\"\n",
+		"version.txt": "version 1.4.0-beta.2+sha.5114f85\n",
+	})
+
+	project, err := dover.LoadProject(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(project.Matches()))
+	match := project.Matches()[0]
+	assert.Equal(t, "main.go", match.File())
+	assert.Equal(t, 3, match.Line())
+	assert.Equal(t, "1.4.0-beta.2+sha.5114f85", match.Text())
+
+	current, err := project.Current()
+	assert.Nil(t, err)
+	assert.Equal(t, "1", current.Major())
+	assert.Equal(t, "4", current.Minor())
+	assert.Equal(t, "0", current.Patch())
+	assert.Equal(t, "beta", current.PreRelease())
+	assert.Equal(t, "2", current.Build())
+	assert.Equal(t, []string{"sha", "5114f85"}, current.Metadata())
+	assert.Equal(t, 0, current.Compare(match.Version()))
+
+	plan, err := project.Plan(dover.Bump{Part: dover.Minor, PreRelease: "alpha"})
+	assert.Nil(t, err)
+	assert.Equal(t, "1.5.0-alpha.0", plan.Next.String())
+	assert.Equal(t, 1, plan.Next.Compare(current))
+	assert.Equal(t, 2, len(plan.Changes))
+	assert.Equal(t, "1.5.0-alpha.0", plan.Changes[1].New)
+
+	assert.Nil(t, project.Apply(plan))
+	assert.Equal(t, "version 1.5.0-alpha.0\n", readFile(t, dir, "version.txt"))
+	current, err = project.Current()
+	assert.Nil(t, err)
+	assert.Equal(t, "alpha", current.PreRelease())
+	assert.Equal(t, "0", current.Build())
+}
+
+func TestVersionAccessorsOfARelease(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		".dover":  "[dover]\nversioned_files = [\"main.go\"]\n",
+		"main.go": "package main\n\nconst VERSION = \"2.0.0\"\n",
+	})
+	project, err := dover.LoadProject(dir)
+	assert.Nil(t, err)
+
+	current, err := project.Current()
+	assert.Nil(t, err)
+	assert.Equal(t, "", current.PreRelease())
+	assert.Equal(t, "", current.Build())
+	assert.Nil(t, current.Identifiers())
+	assert.Nil(t, current.Metadata())
+
+	formatted, err := current.Format("000")
+	assert.Nil(t, err)
+	assert.Equal(t, "2.0.0", formatted)
+	_, err = current.Format("0X")
+	var formatError *dover.InvalidFormatError
+	assert.True(t, errors.As(err, &formatError))
+}
+
+func TestErrors(t *testing.T) {
+	_, err := dover.LoadProject(t.TempDir())
+	var configError *dover.ConfigError
+	assert.True(t, errors.As(err, &configError))
+
+	dir := writeProject(t, map[string]string{
+		".dover":      "[dover]\nversioned_files = [\"main.go\", \"version.txt\"]\n",
+		"main.go":     "package main\n",
+		"version.txt": "no version here\n",
+	})
+	project, err := dover.LoadProject(dir)
+	assert.Nil(t, err)
+	_, err = project.Current()
+	var noVersionError *dover.NoVersionError
+	assert.True(t, errors.As(err, &noVersionError))
+	assert.Equal(t, []string{"main.go", "version.txt"}, noVersionError.Files)
+
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nconst VERSION = \"1.0.0\"\n"), 0666)
+	os.WriteFile(filepath.Join(dir, "version.txt"), []byte("version 1.1.0\n"), 0666)
+	project, err = dover.LoadProject(dir)
+	assert.Nil(t, err)
+	_, err = project.Plan(dover.Bump{Part: dover.Patch})
+	var inconsistentError *dover.InconsistentVersionError
+	assert.True(t, errors.As(err, &inconsistentError))
+	assert.Equal(t, 2, len(inconsistentError.Matches))
+
+	os.WriteFile(filepath.Join(dir, "version.txt"), []byte("version 1.0.0-beta.1\n"), 0666)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nconst VERSION = \"1.0.0-beta.1\"\n"), 0666)
+	project, err = dover.LoadProject(dir)
+	assert.Nil(t, err)
+	_, err = project.Plan(dover.Bump{PreRelease: "alpha"})
+	var releaseOrderError *dover.ReleaseOrderError
+	assert.True(t, errors.As(err, &releaseOrderError))
+}